@@ -11,6 +11,9 @@ import (
 
 	"github.com/go-faster/jx"
 	jsoniter "github.com/json-iterator/go"
+	"github.com/romshark/jscan-benchmark/jscanversions"
+	"github.com/romshark/jscan-benchmark/test"
+	"github.com/romshark/jscan-benchmark/variants"
 	"github.com/romshark/jscan/v2"
 	"github.com/valyala/fastjson"
 
@@ -21,49 +24,83 @@ type Decoder interface {
 	DecodeArray2D(str []byte) ([][]bool, error)
 }
 
-var implementations = []struct {
+type implementation struct {
 	Name string
 	Make func() Decoder
-}{
-	{
-		Name: "romshark_jscan",
-		Make: func() Decoder {
-			return DecoderJscan{Parser: jscan.NewParser[[]byte](8)}
-		},
-	},
-	{
+}
+
+// jscanStack reports 8 preallocated stack frames under the bare name
+// as benchmarked before variants were introduced.
+var jscanStack = variants.WithBare(variants.JscanStack, "stack8")
+
+var implementations = func() []implementation {
+	var l []implementation
+	for _, v := range jscanStack {
+		v := v
+		l = append(l, implementation{
+			Name: v.Library("romshark_jscan"),
+			Make: func() Decoder {
+				return DecoderJscan{Parser: jscan.NewParser[[]byte](v.Config)}
+			},
+		})
+	}
+	for _, v := range jscanversions.Versions {
+		v := v
+		l = append(l, implementation{
+			Name: "romshark_" + v.Name,
+			Make: func() Decoder {
+				return DecoderJscanVersion{
+					Scan: v.NewParser(variants.Bare(jscanStack).Config),
+				}
+			},
+		})
+	}
+	l = append(l, implementation{
 		Name: "encoding_json",
 		Make: func() Decoder {
 			return DecoderEncodingJson{}
 		},
-	},
-	{
-		Name: "jsoniter_unmarshal",
-		Make: func() Decoder {
-			return DecoderJsoniterUnmarshal{}
-		},
-	},
-	{
-		Name: "jsoniter_iterator",
-		Make: func() Decoder {
-			return DecoderJsoniterIterator{
-				Iterator: jsoniter.NewIterator(jsoniter.ConfigDefault),
-			}
-		},
-	},
-	{
+	})
+	for _, v := range variants.Jsoniter {
+		v := v
+		l = append(l, implementation{
+			Name: v.Library("jsoniter_unmarshal"),
+			Make: func() Decoder {
+				return DecoderJsoniterUnmarshal{API: v.Config}
+			},
+		})
+	}
+	for _, v := range variants.Jsoniter {
+		v := v
+		l = append(l, implementation{
+			Name: v.Library("jsoniter_iterator"),
+			Make: func() Decoder {
+				return DecoderJsoniterIterator{
+					Iterator: jsoniter.NewIterator(v.Config),
+				}
+			},
+		})
+	}
+	l = append(l, implementation{
 		Name: "gofaster_jx",
 		Make: func() Decoder {
 			return DecoderGofasterJx{Decoder: new(jx.Decoder)}
 		},
-	},
-	{
-		Name: "valyala_fastjson",
-		Make: func() Decoder {
-			return DecoderValyalaFastjson{Parser: new(fastjson.Parser)}
-		},
-	},
-}
+	})
+	for _, v := range variants.FastjsonReuse {
+		v := v
+		l = append(l, implementation{
+			Name: v.Library("valyala_fastjson"),
+			Make: func() Decoder {
+				if !v.Config {
+					return DecoderValyalaFastjson{}
+				}
+				return DecoderValyalaFastjson{Parser: new(fastjson.Parser)}
+			},
+		})
+	}
+	return l
+}()
 
 type Test struct {
 	Name      string
@@ -169,11 +206,20 @@ func BenchmarkDecode2DArray(b *testing.B) {
 	var err error
 	for _, td := range tests {
 		in := []byte(td.Input)
+		tp := test.Throughput{Bytes: len(in), Documents: 1}
+		if !td.ExpectErr {
+			tp.Values = test.CountValues(in)
+		}
+		input := test.Input{
+			Name:       td.Name,
+			Categories: []string{test.SizeCategory(len(in))},
+		}
 		b.Run(td.Name, func(b *testing.B) {
+			test.SkipUnselected(b, input)
 			for _, ti := range implementations {
 				d := ti.Make()
 				if td.ExpectErr {
-					b.Run(ti.Name, func(b *testing.B) {
+					test.Run(b, ti.Name, tp, func(b *testing.B) {
 						for n := 0; n < b.N; n++ {
 							if a, err = d.DecodeArray2D(in); err == nil {
 								b.Fatal("expected error")
@@ -181,7 +227,7 @@ func BenchmarkDecode2DArray(b *testing.B) {
 						}
 					})
 				} else {
-					b.Run(ti.Name, func(b *testing.B) {
+					test.Run(b, ti.Name, tp, func(b *testing.B) {
 						for n := 0; n < b.N; n++ {
 							if a, err = d.DecodeArray2D(in); err != nil {
 								b.Fatalf("unexpected error: %v", err)
@@ -198,39 +244,70 @@ func BenchmarkDecode2DArray(b *testing.B) {
 type DecoderJscan struct{ *jscan.Parser[[]byte] }
 
 func (d DecoderJscan) DecodeArray2D(str []byte) ([][]bool, error) {
-	var s [][]bool
-	currentIndex := 0
+	var a array2D
 	err := d.Parser.Scan(str, func(i *jscan.Iterator[[]byte]) (err bool) {
-		switch i.Level() {
-		case 0: // Root array
-			if i.ValueType() != jscan.ValueTypeArray {
-				return true
-			}
-			s = make([][]bool, 0, 128)
-			return false
-		case 1: // Sub-array
-			if i.ValueType() != jscan.ValueTypeArray {
-				return true
-			}
-			currentIndex = len(s)
-			s = append(s, make([]bool, 0, 32))
-			return false
-		}
-		switch i.ValueType() {
-		case jscan.ValueTypeTrue:
-			s[currentIndex] = append(s[currentIndex], true)
-		case jscan.ValueTypeFalse:
-			s[currentIndex] = append(s[currentIndex], false)
-		default:
-			// Unexpected array element type
-			return true
+		if i.Level() < 2 {
+			return a.open(i.Level(), i.ValueType())
 		}
-		return false
+		return a.add(i.ValueType())
 	})
 	if err.IsErr() {
 		return nil, err
 	}
-	return s, nil
+	return a.s, nil
+}
+
+// DecoderJscanVersion is DecoderJscan for a registered jscan version.
+type DecoderJscanVersion struct{ Scan jscanversions.ScanFunc }
+
+func (d DecoderJscanVersion) DecodeArray2D(str []byte) ([][]bool, error) {
+	var a array2D
+	err := d.Scan(str, func(i jscanversions.Iterator) (err bool) {
+		if i.Level() < 2 {
+			return a.open(i.Level(), i.ValueType())
+		}
+		return a.add(i.ValueType())
+	})
+	if err != nil {
+		return nil, err
+	}
+	return a.s, nil
+}
+
+// array2D is the array decoded by the jscan decoders.
+type array2D struct {
+	s            [][]bool
+	currentIndex int
+}
+
+// open opens the root array at level 0 or a sub-array at level 1
+// if the value of type t is an array.
+func (a *array2D) open(level int, t jscan.ValueType) (err bool) {
+	if t != jscan.ValueTypeArray {
+		return true
+	}
+	if level == 0 { // Root array
+		a.s = make([][]bool, 0, 128)
+		return false
+	}
+	// Sub-array
+	a.currentIndex = len(a.s)
+	a.s = append(a.s, make([]bool, 0, 32))
+	return false
+}
+
+// add appends the boolean of type t to the current sub-array.
+func (a *array2D) add(t jscan.ValueType) (err bool) {
+	switch t {
+	case jscan.ValueTypeTrue:
+		a.s[a.currentIndex] = append(a.s[a.currentIndex], true)
+	case jscan.ValueTypeFalse:
+		a.s[a.currentIndex] = append(a.s[a.currentIndex], false)
+	default:
+		// Unexpected array element type
+		return true
+	}
+	return false
 }
 
 type DecoderEncodingJson struct{}
@@ -243,11 +320,11 @@ func (DecoderEncodingJson) DecodeArray2D(str []byte) ([][]bool, error) {
 	return s, nil
 }
 
-type DecoderJsoniterUnmarshal struct{}
+type DecoderJsoniterUnmarshal struct{ jsoniter.API }
 
 func (d DecoderJsoniterUnmarshal) DecodeArray2D(str []byte) ([][]bool, error) {
 	var s [][]bool
-	if err := jsoniter.Unmarshal(str, &s); err != nil {
+	if err := d.API.Unmarshal(str, &s); err != nil {
 		return nil, err
 	}
 	return s, nil
@@ -321,10 +398,16 @@ func (d DecoderGofasterJx) DecodeArray2D(str []byte) (a [][]bool, err error) {
 	return a, nil
 }
 
+// DecoderValyalaFastjson allocates a new parser for every
+// operation if Parser is nil.
 type DecoderValyalaFastjson struct{ *fastjson.Parser }
 
 func (d DecoderValyalaFastjson) DecodeArray2D(str []byte) (a [][]bool, err error) {
-	v, err := d.Parser.ParseBytes(str)
+	p := d.Parser
+	if p == nil {
+		p = new(fastjson.Parser)
+	}
+	v, err := p.ParseBytes(str)
 	if err != nil {
 		return nil, err
 	}
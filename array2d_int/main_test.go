@@ -0,0 +1,9 @@
+package array2d_int_test
+
+import (
+	"testing"
+
+	"github.com/romshark/jscan-benchmark/test"
+)
+
+func TestMain(m *testing.M) { test.Main(m) }
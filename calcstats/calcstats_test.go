@@ -4,7 +4,9 @@ import (
 	"fmt"
 	"testing"
 
+	"github.com/romshark/jscan-benchmark/jscanversions"
 	"github.com/romshark/jscan-benchmark/test"
+	"github.com/romshark/jscan-benchmark/variants"
 	"github.com/romshark/jscan/v2"
 
 	gofasterjx "github.com/go-faster/jx"
@@ -13,6 +15,10 @@ import (
 	valyalafastjson "github.com/valyala/fastjson"
 )
 
+// jsoniterVariants reports ConfigFastest under the bare name
+// as benchmarked before variants were introduced.
+var jsoniterVariants = variants.WithBare(variants.Jsoniter, "fastest")
+
 type Stats struct {
 	TotalStrings  int
 	TotalNulls    int
@@ -26,38 +32,15 @@ type Stats struct {
 	MaxArrayLen   int
 }
 
-func MustCalcStatsJscan(p *jscan.Parser[[]byte], str []byte) (s Stats) {
+func MustCalcStatsJscan[S ~string | ~[]byte](p *jscan.Parser[S], str S) (s Stats) {
 	if err := p.Scan(
 		str,
-		func(i *jscan.Iterator[[]byte]) (err bool) {
+		func(i *jscan.Iterator[S]) (err bool) {
 			if i.KeyIndex() != -1 {
 				// Calculate key length excluding the quotes
-				l := i.KeyIndexEnd() - i.KeyIndex() - 2
-				s.TotalKeys++
-				if l > s.MaxKeyLen {
-					s.MaxKeyLen = l
-				}
-			}
-			switch i.ValueType() {
-			case jscan.ValueTypeObject:
-				s.TotalObjects++
-			case jscan.ValueTypeArray:
-				s.TotalArrays++
-			case jscan.ValueTypeNull:
-				s.TotalNulls++
-			case jscan.ValueTypeFalse, jscan.ValueTypeTrue:
-				s.TotalBooleans++
-			case jscan.ValueTypeNumber:
-				s.TotalNumbers++
-			case jscan.ValueTypeString:
-				s.TotalStrings++
-			}
-			if i.Level() > s.MaxDepth {
-				s.MaxDepth = i.Level()
-			}
-			if l := i.ArrayIndex() + 1; l > s.MaxArrayLen {
-				s.MaxArrayLen = l
+				s.addKey(i.KeyIndexEnd() - i.KeyIndex() - 2)
 			}
+			s.addValue(i.ValueType(), i.Level(), i.ArrayIndex())
 			return false
 		},
 	); err.IsErr() {
@@ -66,6 +49,59 @@ func MustCalcStatsJscan(p *jscan.Parser[[]byte], str []byte) (s Stats) {
 	return
 }
 
+// MustCalcStatsJscanVersion is MustCalcStatsJscan for a registered jscan version.
+func MustCalcStatsJscanVersion(scan jscanversions.ScanFunc, str []byte) (s Stats) {
+	if err := scan(
+		str,
+		func(i jscanversions.Iterator) (err bool) {
+			if i.KeyIndex() != -1 {
+				// Calculate key length excluding the quotes
+				s.addKey(i.KeyIndexEnd() - i.KeyIndex() - 2)
+			}
+			s.addValue(i.ValueType(), i.Level(), i.ArrayIndex())
+			return false
+		},
+	); err != nil {
+		panic(fmt.Errorf("unexpected error: %s", err))
+	}
+	return
+}
+
+// addKey counts a key of length l.
+// Small enough to be inlined into the jscan callbacks.
+func (s *Stats) addKey(l int) {
+	s.TotalKeys++
+	if l > s.MaxKeyLen {
+		s.MaxKeyLen = l
+	}
+}
+
+// addValue counts a value of type t at depth level
+// and array index arrayIndex, -1 if not in an array.
+// Small enough to be inlined into the jscan callbacks.
+func (s *Stats) addValue(t jscan.ValueType, level, arrayIndex int) {
+	switch t {
+	case jscan.ValueTypeObject:
+		s.TotalObjects++
+	case jscan.ValueTypeArray:
+		s.TotalArrays++
+	case jscan.ValueTypeNull:
+		s.TotalNulls++
+	case jscan.ValueTypeFalse, jscan.ValueTypeTrue:
+		s.TotalBooleans++
+	case jscan.ValueTypeNumber:
+		s.TotalNumbers++
+	case jscan.ValueTypeString:
+		s.TotalStrings++
+	}
+	if level > s.MaxDepth {
+		s.MaxDepth = level
+	}
+	if l := arrayIndex + 1; l > s.MaxArrayLen {
+		s.MaxArrayLen = l
+	}
+}
+
 func MustCalcStatsJsoniter(p *jsoniter.Iterator, str []byte) (s Stats) {
 	p.ResetBytes(str)
 	var readValue func(lv int, k string, ai int, i *jsoniter.Iterator)
@@ -190,12 +226,25 @@ func MustCalcStatsGofasterJx(p *gofasterjx.Decoder, str []byte) (s Stats) {
 	return
 }
 
-func MustCalcStatsValyalaFastjson(p *valyalafastjson.Parser, str []byte) (s Stats) {
+func MustCalcStatsValyalaFastjson(p *valyalafastjson.Parser, str []byte) Stats {
 	v, err := p.ParseBytes(str)
 	if err != nil {
 		panic(err)
 	}
+	return mustCalcStatsValyalaFastjsonValue(v)
+}
+
+// MustCalcStatsValyalaFastjsonString is MustCalcStatsValyalaFastjson
+// for string inputs.
+func MustCalcStatsValyalaFastjsonString(p *valyalafastjson.Parser, str string) Stats {
+	v, err := p.Parse(str)
+	if err != nil {
+		panic(err)
+	}
+	return mustCalcStatsValyalaFastjsonValue(v)
+}
 
+func mustCalcStatsValyalaFastjsonValue(v *valyalafastjson.Value) (s Stats) {
 	var parseValue func(v *valyalafastjson.Value, lv int, k []byte, a int) error
 	parseValue = func(
 		v *valyalafastjson.Value,
@@ -299,14 +348,28 @@ func TestImplementations(t *testing.T) {
 		MaxArrayLen:   5,
 	}
 
-	t.Run("jscan___________", func(t *testing.T) {
-		p := jscan.NewParser[[]byte](64)
-		require.Equal(t, expect, MustCalcStatsJscan(p, []byte(input)))
-	})
-	t.Run("jsoniter________", func(t *testing.T) {
-		p := jsoniter.NewIterator(jsoniter.ConfigFastest)
-		require.Equal(t, expect, MustCalcStatsJsoniter(p, []byte(input)))
+	for _, v := range variants.JscanStack {
+		t.Run(test.Pad(v.Library("jscan"), 16), func(t *testing.T) {
+			p := jscan.NewParser[[]byte](v.Config)
+			require.Equal(t, expect, MustCalcStatsJscan(p, []byte(input)))
+		})
+	}
+	t.Run(test.Pad("jscan_string", 16), func(t *testing.T) {
+		p := jscan.NewParser[string](64)
+		require.Equal(t, expect, MustCalcStatsJscan(p, input))
 	})
+	for _, v := range jscanversions.Versions {
+		t.Run(test.Pad(v.Name, 16), func(t *testing.T) {
+			scan := v.NewParser(64)
+			require.Equal(t, expect, MustCalcStatsJscanVersion(scan, []byte(input)))
+		})
+	}
+	for _, v := range jsoniterVariants {
+		t.Run(test.Pad(v.Library("jsoniter"), 16), func(t *testing.T) {
+			p := jsoniter.NewIterator(v.Config)
+			require.Equal(t, expect, MustCalcStatsJsoniter(p, []byte(input)))
+		})
+	}
 	t.Run("gofaster_jx_____", func(t *testing.T) {
 		p := new(gofasterjx.Decoder)
 		require.Equal(t, expect, MustCalcStatsGofasterJx(p, []byte(input)))
@@ -316,59 +379,93 @@ func TestImplementations(t *testing.T) {
 		p := new(valyalafastjson.Parser)
 		require.Equal(t, expect, MustCalcStatsValyalaFastjson(p, []byte(input)))
 	})
+
+	t.Run("valyala_fastjson_string", func(t *testing.T) {
+		p := new(valyalafastjson.Parser)
+		require.Equal(t, expect, MustCalcStatsValyalaFastjsonString(p, input))
+	})
 }
 
 var gs Stats
 
 func BenchmarkCalcStats(b *testing.B) {
-	for _, bd := range []struct {
-		name  string
-		input test.SourceProvider
-	}{
-		{"miniscule_1b__________", test.SrcFile("miniscule_1b.json")},
-		{"tiny_8b_______________", test.SrcFile("tiny_8b.json")},
-		{"small_336b____________", test.SrcFile("small_336b.json")},
-		{"large_26m_____________", test.SrcFile("large_26m.json.gz")},
-		{"nasa_SxSW_2016_125k___", test.SrcFile("nasa_SxSW_2016_125k.json.gz")},
-		{"escaped_3k____________", test.SrcFile("escaped_3k.json")},
-		{"array_int_1024_12k____", test.SrcFile("array_int_1024_12k.json")},
-		{"array_dec_1024_10k____", test.SrcFile("array_dec_1024_10k.json")},
-		{"array_nullbool_1024_5k", test.SrcFile("array_nullbool_1024_5k.json")},
-		{"array_str_1024_639k___", test.SrcFile("array_str_1024_639k.json")},
-	} {
-		b.Run(bd.name, func(b *testing.B) {
-			src, err := bd.input.GetJSON()
+	for _, bd := range test.Corpus {
+		b.Run(bd.BenchName(), func(b *testing.B) {
+			test.SkipUnselected(b, bd)
+			src, err := bd.Source.GetJSON()
 			require.NoError(b, err)
+			tp := bd.Throughput(src)
 
-			b.Run("jscan___________", func(b *testing.B) {
-				p := jscan.NewParser[[]byte](1024)
-				b.ResetTimer()
-				for i := 0; i < b.N; i++ {
-					gs = MustCalcStatsJscan(p, src)
-				}
-			})
+			for _, v := range variants.JscanStack {
+				test.Run(b, test.Pad(v.Library("jscan"), 16), tp, func(b *testing.B) {
+					p := jscan.NewParser[[]byte](v.Config)
+					test.ResetTimer(b)
+					for i := 0; i < b.N; i++ {
+						gs = MustCalcStatsJscan(p, src)
+					}
+				})
+			}
 
-			b.Run("jsoniter________", func(b *testing.B) {
-				p := jsoniter.NewIterator(jsoniter.ConfigFastest)
-				b.ResetTimer()
+			// Inputs held as strings, converted before the timer starts.
+			test.Run(b, test.Pad("jscan_string", 16), tp, func(b *testing.B) {
+				p := jscan.NewParser[string](1024)
+				str := string(src)
+				test.ResetTimer(b)
 				for i := 0; i < b.N; i++ {
-					gs = MustCalcStatsJsoniter(p, src)
+					gs = MustCalcStatsJscan(p, str)
 				}
 			})
 
-			b.Run("gofaster_jx_____", func(b *testing.B) {
+			for _, v := range jscanversions.Versions {
+				test.Run(b, test.Pad(v.Name, 16), tp, func(b *testing.B) {
+					scan := v.NewParser(1024)
+					test.ResetTimer(b)
+					for i := 0; i < b.N; i++ {
+						gs = MustCalcStatsJscanVersion(scan, src)
+					}
+				})
+			}
+
+			for _, v := range jsoniterVariants {
+				test.Run(b, test.Pad(v.Library("jsoniter"), 16), tp, func(b *testing.B) {
+					p := jsoniter.NewIterator(v.Config)
+					test.ResetTimer(b)
+					for i := 0; i < b.N; i++ {
+						gs = MustCalcStatsJsoniter(p, src)
+					}
+				})
+			}
+
+			test.Run(b, "gofaster_jx_____", tp, func(b *testing.B) {
 				p := new(gofasterjx.Decoder)
-				b.ResetTimer()
+				test.ResetTimer(b)
 				for i := 0; i < b.N; i++ {
 					gs = MustCalcStatsGofasterJx(p, src)
 				}
 			})
 
-			b.Run("valyala_fastjson", func(b *testing.B) {
+			for _, v := range variants.FastjsonReuse {
+				test.Run(b, v.Library("valyala_fastjson"), tp, func(b *testing.B) {
+					if !v.Config {
+						for i := 0; i < b.N; i++ {
+							gs = MustCalcStatsValyalaFastjson(new(valyalafastjson.Parser), src)
+						}
+						return
+					}
+					p := new(valyalafastjson.Parser)
+					test.ResetTimer(b)
+					for i := 0; i < b.N; i++ {
+						gs = MustCalcStatsValyalaFastjson(p, src)
+					}
+				})
+			}
+
+			test.Run(b, "valyala_fastjson_string", tp, func(b *testing.B) {
 				p := new(valyalafastjson.Parser)
-				b.ResetTimer()
+				str := string(src)
+				test.ResetTimer(b)
 				for i := 0; i < b.N; i++ {
-					gs = MustCalcStatsValyalaFastjson(p, src)
+					gs = MustCalcStatsValyalaFastjsonString(p, str)
 				}
 			})
 		})
@@ -0,0 +1,9 @@
+package calcstats
+
+import (
+	"testing"
+
+	"github.com/romshark/jscan-benchmark/test"
+)
+
+func TestMain(m *testing.M) { test.Main(m) }
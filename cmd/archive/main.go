@@ -0,0 +1,82 @@
+// Command archive records the output of `go test -bench` as a results archive
+// annotated with the machine and date it was recorded on.
+// Results containing noisy cases or cases with fewer samples than required
+// to assess noise are rejected unless -force is used.
+//
+//	go test -bench . -benchmem ./... -count 6 | go run ./cmd/archive -o results.json
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/romshark/jscan-benchmark/results"
+)
+
+func main() {
+	if err := run(os.Args[1:], os.Stdin); err != nil {
+		fmt.Fprintf(os.Stderr, "archive: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(args []string, stdin io.Reader) error {
+	hostname, _ := os.Hostname()
+
+	f := flag.NewFlagSet("archive", flag.ContinueOnError)
+	fMachine := f.String("machine", hostname, "name of the machine")
+	fDate := f.String("date", "", "date of the run (RFC 3339), defaults to now")
+	fOut := f.String("o", "", "output file (required)")
+	fMaxCV := f.Float64("max-cv", results.DefaultMaxCV,
+		"maximum coefficient of variation of a trustworthy case")
+	fForce := f.Bool("force", false,
+		"archive even if results are noisy or have insufficient samples")
+	if err := f.Parse(args); err != nil {
+		return err
+	}
+	if *fOut == "" {
+		return fmt.Errorf("missing output file")
+	}
+
+	date := time.Now()
+	if *fDate != "" {
+		var err error
+		if date, err = time.Parse(time.RFC3339, *fDate); err != nil {
+			return fmt.Errorf("parsing date: %w", err)
+		}
+	}
+
+	var s *results.Set
+	if f.NArg() > 0 {
+		a, err := results.ReadFile(f.Arg(0))
+		if err != nil {
+			return err
+		}
+		s = &a.Set
+	} else {
+		var err error
+		if s, err = results.Parse(stdin); err != nil {
+			return err
+		}
+	}
+	if len(s.Results) < 1 {
+		return fmt.Errorf("no benchmark results")
+	}
+
+	d := results.Diagnostics{MaxCV: *fMaxCV}
+	if untrusted := d.Untrusted(s); len(untrusted) > 0 {
+		for _, c := range untrusted {
+			fmt.Fprintf(os.Stderr, "%s: %s\n", c.Key, d.Diagnose(c).Verdict(d))
+		}
+		if !*fForce {
+			return fmt.Errorf("%d noisy cases or cases with insufficient samples, "+
+				"use -force to archive anyway", len(untrusted))
+		}
+	}
+
+	a := &results.Archive{Machine: *fMachine, Date: date.UTC(), Set: *s}
+	return a.WriteFile(*fOut)
+}
@@ -0,0 +1,316 @@
+// Command binsize compares the binary size and build time cost of the libraries.
+// It generates a minimal program per library and operation (validate, scan),
+// builds each with an empty build cache and reports the stripped binary size,
+// the build time and the number of linked packages relative to a program
+// using no JSON library at all. Given benchmark results it also reports
+// the runtime speedup of each library relative to jscan.
+//
+//	go run ./cmd/binsize
+//	go run ./cmd/binsize -filter 'validate_(jscan|sonic)' results.json
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/romshark/jscan-benchmark/results"
+)
+
+func main() {
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer cancel()
+	if err := run(ctx, os.Args[1:], os.Stdout); err != nil {
+		fmt.Fprintf(os.Stderr, "binsize: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(ctx context.Context, args []string, w io.Writer) error {
+	f := flag.NewFlagSet("binsize", flag.ContinueOnError)
+	fFilter := f.String("filter", "",
+		"regular expression matched against <operation>_<library>")
+	fWarm := f.Bool("warm", false,
+		"share the build cache between builds, build times then only "+
+			"include what isn't cached yet")
+	fKeep := f.Bool("keep", false, "keep the generated programs and binaries")
+	if err := f.Parse(args); err != nil {
+		return err
+	}
+	filter, err := regexp.Compile(*fFilter)
+	if err != nil {
+		return fmt.Errorf("parsing filter: %w", err)
+	}
+
+	var scores map[string]map[string]float64
+	if f.NArg() > 0 {
+		s, err := readSet(f.Args())
+		if err != nil {
+			return err
+		}
+		scores = map[string]map[string]float64{}
+		sc := results.Scoring{Baseline: results.DefaultBaseline}
+		for _, x := range sc.Scores(s) {
+			scores[x.Library] = x.Suites
+		}
+	}
+
+	root, err := moduleRoot(ctx)
+	if err != nil {
+		return err
+	}
+	// Programs must reside in the module to use its dependency versions.
+	// Directories prefixed with "_" are ignored by ./... patterns.
+	dir, err := os.MkdirTemp(root, "_binsize")
+	if err != nil {
+		return err
+	}
+	if !*fKeep {
+		defer os.RemoveAll(dir)
+	} else {
+		fmt.Fprintf(os.Stderr, "keeping programs in %s\n", dir)
+	}
+
+	b := builder{
+		root:   root,
+		dir:    dir,
+		cache:  filepath.Join(dir, "cache"),
+		shared: *fWarm,
+	}
+
+	fmt.Fprintf(os.Stderr, "building %s\n", Baseline.Name())
+	base, err := b.build(ctx, Baseline)
+	if err != nil {
+		return err
+	}
+	var l []Build
+	for _, p := range Programs {
+		if !filter.MatchString(p.Name()) {
+			continue
+		}
+		fmt.Fprintf(os.Stderr, "building %s\n", p.Name())
+		r, err := b.build(ctx, p)
+		if err != nil {
+			return err
+		}
+		l = append(l, r)
+	}
+	return writeMarkdown(w, base, l, scores, *fWarm)
+}
+
+// Build is the result of building a program.
+type Build struct {
+	Program
+	Size       int64 // Bytes of the stripped binary
+	Time       time.Duration
+	Packages   int // Linked packages
+	ThirdParty int // Linked packages outside of the standard library
+}
+
+type builder struct {
+	root, dir, cache string
+	shared           bool // Share the build cache between builds
+}
+
+// build generates, builds and smoke tests p.
+func (b builder) build(ctx context.Context, p Program) (r Build, err error) {
+	r.Program = p
+	src, err := p.Source()
+	if err != nil {
+		return r, err
+	}
+	pkgDir := filepath.Join(b.dir, p.Name())
+	if err := os.MkdirAll(pkgDir, 0o755); err != nil {
+		return r, err
+	}
+	if err := os.WriteFile(filepath.Join(pkgDir, "main.go"), src, 0o644); err != nil {
+		return r, err
+	}
+	rel, err := filepath.Rel(b.root, pkgDir)
+	if err != nil {
+		return r, err
+	}
+	pkg := "./" + filepath.ToSlash(rel)
+
+	cache := b.cache
+	if !b.shared {
+		cache = filepath.Join(b.cache, p.Name())
+	}
+	bin := filepath.Join(b.dir, "bin", p.Name())
+	c := b.command(ctx, cache, "build", "-trimpath", "-ldflags=-s -w", "-o", bin, pkg)
+	start := time.Now()
+	if _, err := output(c); err != nil {
+		return r, fmt.Errorf("building %s: %w", p.Name(), err)
+	}
+	r.Time = time.Since(start)
+	if !b.shared {
+		// Empty build caches are large, remove them right away.
+		if err := os.RemoveAll(cache); err != nil {
+			return r, err
+		}
+	}
+
+	fi, err := os.Stat(bin)
+	if err != nil {
+		return r, err
+	}
+	r.Size = fi.Size()
+
+	if r.Packages, r.ThirdParty, err = b.packages(ctx, pkg); err != nil {
+		return r, err
+	}
+
+	smoke := exec.CommandContext(ctx, bin)
+	smoke.Stdin = strings.NewReader(SmokeInput)
+	out, err := output(smoke)
+	if err != nil {
+		return r, fmt.Errorf("running %s: %w", p.Name(), err)
+	}
+	if exp, ok := SmokeExpect[p.Op]; ok && strings.TrimSpace(string(out)) != exp {
+		return r, fmt.Errorf("%s printed %q for %s, expected %q",
+			p.Name(), strings.TrimSpace(string(out)), SmokeInput, exp)
+	}
+	return r, nil
+}
+
+// packages returns the number of packages linked into pkg
+// and how many of them aren't part of the standard library.
+func (b builder) packages(ctx context.Context, pkg string) (all, thirdParty int, err error) {
+	c := b.command(ctx, filepath.Join(b.cache, "list"), "list", "-deps", "-json=Standard", pkg)
+	out, err := output(c)
+	if err != nil {
+		return 0, 0, fmt.Errorf("listing dependencies of %s: %w", pkg, err)
+	}
+	d := json.NewDecoder(bytes.NewReader(out))
+	for d.More() {
+		var p struct{ Standard bool }
+		if err := d.Decode(&p); err != nil {
+			return 0, 0, err
+		}
+		all++
+		if !p.Standard {
+			thirdParty++
+		}
+	}
+	// The program itself isn't a dependency.
+	return all - 1, thirdParty - 1, nil
+}
+
+func (b builder) command(ctx context.Context, cache string, args ...string) *exec.Cmd {
+	c := exec.CommandContext(ctx, "go", args...)
+	c.Dir = b.root
+	c.Env = append(os.Environ(), "GOCACHE="+cache)
+	return c
+}
+
+func writeMarkdown(
+	w io.Writer, base Build, l []Build, scores map[string]map[string]float64, warm bool,
+) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "### Binary size and build time\n\n")
+	fmt.Fprintf(&b, "Relative to a program using no JSON library "+
+		"(%s stripped, built in %s, %d packages).\n\n",
+		formatBytes(base.Size), formatDuration(base.Time), base.Packages)
+	b.WriteString("|operation|library|stripped size|+size|build time|+build time|packages|third-party|")
+	if scores != nil {
+		b.WriteString("speedup|")
+	}
+	b.WriteString("\n|-|-|-:|-:|-:|-:|-:|-:|")
+	if scores != nil {
+		b.WriteString("-:|")
+	}
+	b.WriteByte('\n')
+	for _, r := range l {
+		// Builds sharing the cache don't compile the standard library again.
+		dt := "-"
+		if !warm {
+			dt = formatDuration(r.Time - base.Time)
+		}
+		fmt.Fprintf(&b, "|%s|%s|%s|%s|%s|%s|%d|%d|",
+			r.Op, r.Library,
+			formatBytes(r.Size), formatBytes(r.Size-base.Size),
+			formatDuration(r.Time), dt,
+			r.Packages, r.ThirdParty)
+		if scores != nil {
+			if s, ok := scores[r.Library][suiteOf[r.Op]]; ok {
+				fmt.Fprintf(&b, "%.2fx|", s)
+			} else {
+				b.WriteString("|")
+			}
+		}
+		b.WriteByte('\n')
+	}
+	b.WriteByte('\n')
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func formatBytes(n int64) string {
+	return fmt.Sprintf("%.2f MiB", float64(n)/(1<<20))
+}
+
+func formatDuration(d time.Duration) string {
+	return fmt.Sprintf("%.1fs", d.Seconds())
+}
+
+// readSet reads and merges the results of all files.
+// Files ending with .json are read as archives,
+// all others as benchmark output.
+func readSet(files []string) (*results.Set, error) {
+	s := new(results.Set)
+	for _, name := range files {
+		if strings.HasSuffix(name, ".json") {
+			a, err := results.ReadFile(name)
+			if err != nil {
+				return nil, err
+			}
+			s.Results = append(s.Results, a.Results...)
+			continue
+		}
+		f, err := os.Open(name)
+		if err != nil {
+			return nil, err
+		}
+		p, err := results.Parse(f)
+		f.Close()
+		if err != nil {
+			return nil, fmt.Errorf("parsing %s: %w", name, err)
+		}
+		s.Results = append(s.Results, p.Results...)
+	}
+	return s, nil
+}
+
+// moduleRoot returns the directory of the main module.
+func moduleRoot(ctx context.Context) (string, error) {
+	out, err := output(exec.CommandContext(ctx, "go", "env", "GOMOD"))
+	if err != nil {
+		return "", err
+	}
+	gomod := strings.TrimSpace(string(out))
+	if gomod == "" || gomod == os.DevNull {
+		return "", fmt.Errorf("not in a module")
+	}
+	return filepath.Dir(gomod), nil
+}
+
+// output runs c and returns its standard output.
+func output(c *exec.Cmd) ([]byte, error) {
+	var stderr bytes.Buffer
+	c.Stderr = &stderr
+	out, err := c.Output()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", err, stderr.String())
+	}
+	return out, nil
+}
@@ -0,0 +1,248 @@
+package main
+
+import (
+	"bytes"
+	"text/template"
+)
+
+// Operations performed by the programs.
+const (
+	OpValidate = "validate"
+	OpScan     = "scan"
+)
+
+// suiteOf maps operations to the suite benchmarking them at runtime.
+var suiteOf = map[string]string{
+	OpValidate: "validation",
+	OpScan:     "calcstats",
+}
+
+// Program is a minimal program performing operation Op on the JSON read
+// from stdin using Library and printing the result of Expr.
+// Validating programs print whether the input is valid,
+// scanning programs print the number of values.
+type Program struct {
+	Op      string
+	Library string // Named like in the benchmark results
+	Imports []string
+	Decls   string // Helper declarations
+	Expr    string // Expression over src []byte
+}
+
+// Name returns the unique name of the program.
+func (p Program) Name() string {
+	if p.Op == "" {
+		return p.Library
+	}
+	return p.Op + "_" + p.Library
+}
+
+// SmokeExpect is the output of the programs of every operation
+// for SmokeInput, which every built program is checked against.
+var (
+	SmokeInput  = `{"a":[1,true,null,"x"]}`
+	SmokeExpect = map[string]string{OpValidate: "true", OpScan: "6"}
+)
+
+// Baseline is a program that doesn't use any JSON library
+// all other programs are compared against.
+var Baseline = Program{Library: "baseline", Expr: "len(src)"}
+
+// Programs are all programs except Baseline.
+var Programs = []Program{
+	{
+		Op: OpValidate, Library: "jscan",
+		Imports: []string{"github.com/romshark/jscan/v2"},
+		Expr:    "jscan.Valid(src)",
+	},
+	{
+		Op: OpValidate, Library: "encoding_json",
+		Imports: []string{"encoding/json"},
+		Expr:    "json.Valid(src)",
+	},
+	{
+		Op: OpValidate, Library: "jsoniter",
+		Imports: []string{"github.com/json-iterator/go"},
+		Expr:    "jsoniter.ConfigDefault.Valid(src)",
+	},
+	{
+		Op: OpValidate, Library: "gofaster_jx",
+		Imports: []string{"github.com/go-faster/jx"},
+		Expr:    "jx.DecodeBytes(src).Validate() == nil",
+	},
+	{
+		Op: OpValidate, Library: "tidwall_gjson",
+		Imports: []string{"github.com/tidwall/gjson"},
+		Expr:    "gjson.ValidBytes(src)",
+	},
+	{
+		Op: OpValidate, Library: "valyala_fastjson",
+		Imports: []string{"github.com/valyala/fastjson"},
+		Expr:    "fastjson.ValidateBytes(src) == nil",
+	},
+	{
+		Op: OpValidate, Library: "goccy_go_json",
+		Imports: []string{"github.com/goccy/go-json"},
+		Expr:    "json.Valid(src)",
+	},
+	{
+		Op: OpValidate, Library: "bytedance_sonic",
+		Imports: []string{"github.com/bytedance/sonic"},
+		Expr:    "sonic.ConfigFastest.Valid(src)",
+	},
+	{
+		Op: OpValidate, Library: "ohler55_ojg_oj",
+		Imports: []string{"github.com/ohler55/ojg/oj"},
+		Expr:    "new(oj.Validator).Validate(src) == nil",
+	},
+	{
+		Op: OpValidate, Library: "minio_simdjson",
+		Imports: []string{"github.com/minio/simdjson-go"},
+		Decls: `func valid(src []byte) bool {
+	_, err := simdjson.Parse(src, nil)
+	return err == nil
+}`,
+		Expr: "valid(src)",
+	},
+	{
+		Op: OpValidate, Library: "jeffail_gabs",
+		Imports: []string{"github.com/Jeffail/gabs"},
+		Decls: `func valid(src []byte) bool {
+	_, err := gabs.ParseJSON(src)
+	return err == nil
+}`,
+		Expr: "valid(src)",
+	},
+	{
+		Op: OpScan, Library: "jscan",
+		Imports: []string{"github.com/romshark/jscan/v2"},
+		Decls: `func count(src []byte) (n int) {
+	if err := jscan.Scan(src, func(*jscan.Iterator[[]byte]) (err bool) {
+		n++
+		return false
+	}); err.IsErr() {
+		return -1
+	}
+	return n
+}`,
+		Expr: "count(src)",
+	},
+	{
+		Op: OpScan, Library: "jsoniter",
+		Imports: []string{"github.com/json-iterator/go"},
+		Decls: `func count(it *jsoniter.Iterator) (n int) {
+	n = 1
+	switch it.WhatIsNext() {
+	case jsoniter.ObjectValue:
+		it.ReadMapCB(func(it *jsoniter.Iterator, _ string) bool {
+			n += count(it)
+			return true
+		})
+	case jsoniter.ArrayValue:
+		it.ReadArrayCB(func(it *jsoniter.Iterator) bool {
+			n += count(it)
+			return true
+		})
+	default:
+		it.Skip()
+	}
+	return n
+}`,
+		Expr: "count(jsoniter.ParseBytes(jsoniter.ConfigDefault, src))",
+	},
+	{
+		Op: OpScan, Library: "gofaster_jx",
+		Imports: []string{"github.com/go-faster/jx"},
+		Decls: `func count(d *jx.Decoder) (n int, err error) {
+	n = 1
+	switch d.Next() {
+	case jx.Object:
+		err = d.Obj(func(d *jx.Decoder, _ string) error {
+			c, err := count(d)
+			n += c
+			return err
+		})
+	case jx.Array:
+		err = d.Arr(func(d *jx.Decoder) error {
+			c, err := count(d)
+			n += c
+			return err
+		})
+	default:
+		err = d.Skip()
+	}
+	return n, err
+}
+
+func countBytes(src []byte) int {
+	n, err := count(jx.DecodeBytes(src))
+	if err != nil {
+		return -1
+	}
+	return n
+}`,
+		Expr: "countBytes(src)",
+	},
+	{
+		Op: OpScan, Library: "valyala_fastjson",
+		Imports: []string{"github.com/valyala/fastjson"},
+		Decls: `func count(v *fastjson.Value) (n int) {
+	n = 1
+	switch v.Type() {
+	case fastjson.TypeObject:
+		v.GetObject().Visit(func(_ []byte, v *fastjson.Value) {
+			n += count(v)
+		})
+	case fastjson.TypeArray:
+		for _, v := range v.GetArray() {
+			n += count(v)
+		}
+	}
+	return n
+}
+
+func countBytes(src []byte) int {
+	v, err := new(fastjson.Parser).ParseBytes(src)
+	if err != nil {
+		return -1
+	}
+	return count(v)
+}`,
+		Expr: "countBytes(src)",
+	},
+}
+
+var tmplProgram = template.Must(template.New("").Parse(`// Code generated by cmd/binsize. DO NOT EDIT.
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+{{range .Imports}}
+	"{{.}}"
+{{- end}}
+)
+
+{{if .Decls}}{{.Decls}}
+
+{{end -}}
+func main() {
+	src, err := io.ReadAll(os.Stdin)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	fmt.Println({{.Expr}})
+}
+`))
+
+// Source returns the source code of the program.
+func (p Program) Source() ([]byte, error) {
+	var b bytes.Buffer
+	if err := tmplProgram.Execute(&b, p); err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
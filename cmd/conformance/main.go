@@ -0,0 +1,207 @@
+// Command conformance checks all libraries against the JSONTestSuite corpus
+// and the UTF-8 catalog and writes a summary and a table of the outcome
+// per file and library for each.
+// Every library checks the files in a worker process exiting after
+// a timeout so that a library that doesn't return stops running
+// and a crash doesn't abort the check of the remaining files.
+//
+//	go run ./cmd/conformance -o conformance.md
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"os/signal"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/romshark/jscan-benchmark/conformance"
+)
+
+// envWorker makes the command check the files of a corpus given as
+// directory, corpus, library, timeout and first file arguments.
+const envWorker = "JSCANBENCH_CONFORMANCE_WORKER"
+
+// workerErrorPrefix distinguishes failing workers from crashing libraries.
+const workerErrorPrefix = "conformance worker: "
+
+// Titles of the corpora.
+const (
+	titleJSONTestSuite = "JSONTestSuite"
+	titleUTF8          = "UTF-8"
+)
+
+func main() {
+	if os.Getenv(envWorker) != "" {
+		if err := worker(os.Args[1:], os.Stdout); err != nil {
+			fmt.Fprintf(os.Stderr, "%s%v\n", workerErrorPrefix, err)
+			os.Exit(1)
+		}
+		return
+	}
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer cancel()
+	if err := run(ctx, os.Args[1:], os.Stdout); err != nil {
+		fmt.Fprintf(os.Stderr, "conformance: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(ctx context.Context, args []string, stdout io.Writer) error {
+	f := flag.NewFlagSet("conformance", flag.ContinueOnError)
+	fOut := f.String("o", "", "output file (default stdout)")
+	fDir := f.String("dir", "testdata/jsontestsuite", "JSONTestSuite directory")
+	fTimeout := f.Duration("timeout", 10*time.Second,
+		"time after which a library is considered hanging")
+	if err := f.Parse(args); err != nil {
+		return err
+	}
+	exe, err := os.Executable()
+	if err != nil {
+		return err
+	}
+	var matrices []*conformance.Matrix
+	for _, title := range []string{titleJSONTestSuite, titleUTF8} {
+		files, err := corpus(*fDir, title)
+		if err != nil {
+			return err
+		}
+		m := &conformance.Matrix{
+			Title: title, Files: files, Outcomes: make([][]conformance.Outcome, len(files)),
+		}
+		for i := range files {
+			m.Outcomes[i] = make([]conformance.Outcome, len(conformance.Libraries))
+		}
+		for j, l := range conformance.Libraries {
+			m.Libraries = append(m.Libraries, l.Name)
+			outcomes, err := check(ctx, exe, *fDir, title, l.Name, *fTimeout, len(files))
+			if err != nil {
+				return err
+			}
+			for i, o := range outcomes {
+				m.Outcomes[i][j] = o
+			}
+		}
+		matrices = append(matrices, m)
+	}
+	if *fOut == "" {
+		return writeMarkdown(stdout, matrices)
+	}
+	out, err := os.Create(*fOut)
+	if err != nil {
+		return err
+	}
+	if err := writeMarkdown(out, matrices); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+// corpus returns the files of the corpus titled title.
+func corpus(dir, title string) ([]conformance.File, error) {
+	switch title {
+	case titleJSONTestSuite:
+		return conformance.ReadDir(dir)
+	case titleUTF8:
+		return conformance.UTF8, nil
+	}
+	return nil, fmt.Errorf("unknown corpus %q", title)
+}
+
+// check runs worker processes checking the n files of the corpus titled
+// title using library and returns their outcomes. A worker that exits
+// after a timeout is followed by one checking the remaining files,
+// a worker that exits before reporting an outcome crashed checking
+// the next file.
+func check(
+	ctx context.Context, exe, dir, title, library string,
+	timeout time.Duration, n int,
+) ([]conformance.Outcome, error) {
+	var outcomes []conformance.Outcome
+	for len(outcomes) < n {
+		c := exec.CommandContext(ctx, exe,
+			dir, title, library, timeout.String(), strconv.Itoa(len(outcomes)))
+		c.Env = append(os.Environ(), envWorker+"=1")
+		var stdout, stderr bytes.Buffer
+		c.Stdout, c.Stderr = &stdout, &stderr
+		err := c.Run()
+		for _, o := range strings.Fields(stdout.String()) {
+			outcomes = append(outcomes, conformance.Outcome(o))
+		}
+		var exit *exec.ExitError
+		switch {
+		case ctx.Err() != nil:
+			return nil, ctx.Err()
+		case err == nil && len(outcomes) > 0 &&
+			outcomes[len(outcomes)-1] == conformance.OutcomeTimeout:
+			continue
+		case err == nil && len(outcomes) >= n:
+			return outcomes[:n], nil
+		case errors.As(err, &exit) && len(outcomes) < n &&
+			!bytes.HasPrefix(stderr.Bytes(), []byte(workerErrorPrefix)):
+			outcomes = append(outcomes, conformance.OutcomeCrash)
+			continue
+		}
+		return nil, fmt.Errorf("running %s %s: %v: %s", library, title, err, stderr.Bytes())
+	}
+	return outcomes, nil
+}
+
+// worker checks the files of a corpus in order starting at the given
+// index and writes their outcomes line by line.
+// It exits after the first timeout.
+func worker(args []string, w io.Writer) error {
+	if len(args) != 5 {
+		return fmt.Errorf("expected directory, corpus, library, "+
+			"timeout and first file, got %q", args)
+	}
+	files, err := corpus(args[0], args[1])
+	if err != nil {
+		return err
+	}
+	var library *conformance.Library
+	for _, l := range conformance.Libraries {
+		if l.Name == args[2] {
+			l := l
+			library = &l
+		}
+	}
+	if library == nil {
+		return fmt.Errorf("unknown library %q", args[2])
+	}
+	timeout, err := time.ParseDuration(args[3])
+	if err != nil {
+		return fmt.Errorf("parsing timeout: %w", err)
+	}
+	first, err := strconv.Atoi(args[4])
+	if err != nil || first < 0 || first > len(files) {
+		return fmt.Errorf("invalid first file %q", args[4])
+	}
+	for _, f := range files[first:] {
+		o := library.Check(f.Data, timeout)
+		if _, err := fmt.Fprintln(w, o); err != nil {
+			return err
+		}
+		if o == conformance.OutcomeTimeout {
+			return nil
+		}
+	}
+	return nil
+}
+
+func writeMarkdown(w io.Writer, matrices []*conformance.Matrix) error {
+	for _, m := range matrices {
+		if err := m.WriteMarkdown(w); err != nil {
+			return err
+		}
+	}
+	return nil
+}
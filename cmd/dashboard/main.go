@@ -0,0 +1,122 @@
+// Command dashboard generates a single static HTML file from results archives.
+// The file has no external assets and allows filtering by suite, input,
+// library and machine, toggling between absolute and relative metrics
+// and comparing two archives side by side.
+//
+//	go run ./cmd/dashboard -o dashboard.html archives/*.json
+package main
+
+import (
+	_ "embed"
+	"flag"
+	"fmt"
+	"html/template"
+	"io"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/romshark/jscan-benchmark/results"
+)
+
+//go:embed dashboard.html
+var tmplSrc string
+
+var tmpl = template.Must(template.New("dashboard").Parse(tmplSrc))
+
+func main() {
+	if err := run(os.Args[1:], os.Stdout); err != nil {
+		fmt.Fprintf(os.Stderr, "dashboard: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(args []string, stdout io.Writer) error {
+	f := flag.NewFlagSet("dashboard", flag.ContinueOnError)
+	fOut := f.String("o", "", "output file, defaults to stdout")
+	fBaseline := f.String("baseline", results.DefaultBaseline,
+		"default baseline library for relative metrics")
+	if err := f.Parse(args); err != nil {
+		return err
+	}
+	if f.NArg() < 1 {
+		return fmt.Errorf("no archives specified")
+	}
+
+	d := data{Baseline: *fBaseline, Units: results.Units}
+	for _, p := range f.Args() {
+		a, err := results.ReadFile(p)
+		if err != nil {
+			return err
+		}
+		if a.Machine == "" {
+			a.Machine = p
+		}
+		d.Archives = append(d.Archives, newArchiveData(a))
+	}
+	sort.SliceStable(d.Archives, func(i, j int) bool {
+		return d.Archives[i].Date < d.Archives[j].Date
+	})
+
+	w := stdout
+	if *fOut != "" {
+		f, err := os.Create(*fOut)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		w = f
+	}
+	return tmpl.Execute(w, d)
+}
+
+type data struct {
+	Baseline string        `json:"baseline"`
+	Units    []string      `json:"units"`
+	Archives []archiveData `json:"archives"`
+}
+
+type archiveData struct {
+	ID      string     `json:"id"`
+	Machine string     `json:"machine"`
+	Date    string     `json:"date"`
+	CPU     string     `json:"cpu"`
+	Goos    string     `json:"goos"`
+	Goarch  string     `json:"goarch"`
+	Cases   []caseData `json:"cases"`
+}
+
+type caseData struct {
+	results.Key
+	Samples int                `json:"samples"`
+	Metrics map[string]float64 `json:"metrics"`
+}
+
+func newArchiveData(a *results.Archive) archiveData {
+	d := archiveData{
+		ID:      a.ID(),
+		Machine: a.Machine,
+		CPU:     a.CPU,
+		Goos:    a.Goos,
+		Goarch:  a.Goarch,
+	}
+	if !a.Date.IsZero() {
+		d.Date = a.Date.Format(time.RFC3339)
+	}
+	cases := a.Cases()
+	units := results.UnitsOf(cases)
+	for _, c := range cases {
+		cd := caseData{
+			Key:     c.Key,
+			Samples: len(c.Samples),
+			Metrics: make(map[string]float64, len(units)),
+		}
+		for _, u := range units {
+			if v, ok := c.Mean(u); ok {
+				cd.Metrics[u] = v
+			}
+		}
+		d.Cases = append(d.Cases, cd)
+	}
+	return d
+}
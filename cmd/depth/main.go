@@ -0,0 +1,166 @@
+// Command depth sweeps the nesting depth of valid and unterminated arrays
+// and objects from 16 to 1,000,000 levels and reports for each library
+// whether it accepts or rejects the document, overflows the goroutine stack
+// or crashes, as well as the time, heap and stack per check.
+// Every check runs in a subprocess so that a crash doesn't abort the sweep.
+//
+//	go run ./cmd/depth -o depth.md
+//	go run ./cmd/depth -filter '^jscan' -max-depth 65536
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"os/signal"
+	"regexp"
+	"strconv"
+	"time"
+
+	"github.com/romshark/jscan-benchmark/conformance"
+	"github.com/romshark/jscan-benchmark/depth"
+)
+
+// envWorker makes the command check a single document
+// given as library, shape and depth arguments.
+const envWorker = "JSCANBENCH_DEPTH_WORKER"
+
+// workerErrorPrefix distinguishes failing workers from crashing libraries.
+const workerErrorPrefix = "depth worker: "
+
+func main() {
+	if os.Getenv(envWorker) != "" {
+		if err := worker(os.Args[1:], os.Stdout); err != nil {
+			fmt.Fprintf(os.Stderr, "%s%v\n", workerErrorPrefix, err)
+			os.Exit(1)
+		}
+		return
+	}
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer cancel()
+	if err := run(ctx, os.Args[1:], os.Stdout); err != nil {
+		fmt.Fprintf(os.Stderr, "depth: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(ctx context.Context, args []string, stdout io.Writer) error {
+	f := flag.NewFlagSet("depth", flag.ContinueOnError)
+	fOut := f.String("o", "", "output file (default stdout)")
+	fFilter := f.String("filter", "", "regular expression matched against library names")
+	fMaxDepth := f.Int("max-depth", depth.Depths[len(depth.Depths)-1], "maximum depth")
+	fTimeout := f.Duration("timeout", 30*time.Second,
+		"time after which a check is considered hanging")
+	if err := f.Parse(args); err != nil {
+		return err
+	}
+	filter, err := regexp.Compile(*fFilter)
+	if err != nil {
+		return fmt.Errorf("parsing filter: %w", err)
+	}
+	exe, err := os.Executable()
+	if err != nil {
+		return err
+	}
+
+	var libraries []string
+	for _, l := range conformance.Libraries {
+		if filter.MatchString(l.Name) {
+			libraries = append(libraries, l.Name)
+		}
+	}
+	var results []depth.Result
+	for _, s := range depth.Shapes {
+		for _, d := range depth.Depths {
+			if d > *fMaxDepth {
+				continue
+			}
+			for _, l := range libraries {
+				r, err := check(ctx, exe, *fTimeout, l, s.Name, d)
+				if err != nil {
+					return err
+				}
+				fmt.Fprintf(os.Stderr, "%s %s %d: %s\n", l, s.Name, d, r.Outcome)
+				results = append(results, r)
+			}
+		}
+	}
+
+	if *fOut == "" {
+		return depth.WriteMarkdown(stdout, libraries, results)
+	}
+	out, err := os.Create(*fOut)
+	if err != nil {
+		return err
+	}
+	if err := depth.WriteMarkdown(out, libraries, results); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+// check runs a worker process checking the document of the given shape
+// and depth using library and returns its result.
+func check(
+	ctx context.Context, exe string, timeout time.Duration,
+	library, shape string, d int,
+) (depth.Result, error) {
+	r := depth.Result{Library: library, Shape: shape, Depth: d}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	c := exec.CommandContext(ctx, exe, library, shape, strconv.Itoa(d))
+	c.Env = append(os.Environ(), envWorker+"=1")
+	var stdout, stderr bytes.Buffer
+	c.Stdout, c.Stderr = &stdout, &stderr
+	err := c.Run()
+	var exit *exec.ExitError
+	switch {
+	case err == nil:
+		if err := json.Unmarshal(stdout.Bytes(), &r); err != nil {
+			return r, fmt.Errorf("reading result of %s %s %d: %w", library, shape, d, err)
+		}
+		return r, nil
+	case ctx.Err() == context.DeadlineExceeded:
+		r.Outcome = depth.OutcomeTimeout
+		return r, nil
+	case errors.As(err, &exit) && ctx.Err() == nil &&
+		!bytes.HasPrefix(stderr.Bytes(), []byte(workerErrorPrefix)):
+		r.Outcome = depth.OutcomeOfExit(stderr.Bytes())
+		return r, nil
+	}
+	return r, fmt.Errorf("running %s %s %d: %w: %s", library, shape, d, err, stderr.Bytes())
+}
+
+// worker checks a single document and writes the result as JSON.
+func worker(args []string, w io.Writer) error {
+	if len(args) != 3 {
+		return fmt.Errorf("expected library, shape and depth, got %q", args)
+	}
+	var valid func([]byte) bool
+	for _, l := range conformance.Libraries {
+		if l.Name == args[0] {
+			valid = l.New()
+		}
+	}
+	if valid == nil {
+		return fmt.Errorf("unknown library %q", args[0])
+	}
+	s, ok := depth.LookupShape(args[1])
+	if !ok {
+		return fmt.Errorf("unknown shape %q", args[1])
+	}
+	d, err := strconv.Atoi(args[2])
+	if err != nil {
+		return fmt.Errorf("parsing depth: %w", err)
+	}
+	r := depth.Measure(valid, s.Make(d))
+	r.Library, r.Shape, r.Depth = args[0], s.Name, d
+	return json.NewEncoder(w).Encode(r)
+}
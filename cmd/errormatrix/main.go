@@ -0,0 +1,43 @@
+// Command errormatrix checks a catalog of malformed inputs using all
+// libraries and writes tables showing which libraries report the right
+// byte offset and category of the syntax error.
+//
+//	go run ./cmd/errormatrix -o errormatrix.md
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/romshark/jscan-benchmark/errormatrix"
+)
+
+func main() {
+	if err := run(os.Args[1:], os.Stdout); err != nil {
+		fmt.Fprintf(os.Stderr, "errormatrix: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(args []string, stdout io.Writer) error {
+	f := flag.NewFlagSet("errormatrix", flag.ContinueOnError)
+	fOut := f.String("o", "", "output file (default stdout)")
+	if err := f.Parse(args); err != nil {
+		return err
+	}
+	m := errormatrix.Run(errormatrix.Catalog, errormatrix.Libraries)
+	if *fOut == "" {
+		return m.WriteMarkdown(stdout)
+	}
+	out, err := os.Create(*fOut)
+	if err != nil {
+		return err
+	}
+	if err := m.WriteMarkdown(out); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
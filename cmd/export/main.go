@@ -0,0 +1,112 @@
+// Command export converts results archives to CSV, benchstat or
+// OpenMetrics format. OpenMetrics output can optionally be pushed to a
+// Prometheus Pushgateway or any other endpoint accepting the text format.
+//
+//	go run ./cmd/export -format csv archives/*.json > results.csv
+//	go run ./cmd/export -format benchstat archives/m1.json > m1.txt
+//	go run ./cmd/export -format openmetrics -push http://localhost:9091/metrics/job/jscanbench archives/*.json
+package main
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/signal"
+
+	"github.com/romshark/jscan-benchmark/results"
+)
+
+// Formats.
+const (
+	FormatCSV         = "csv"
+	FormatBenchstat   = "benchstat"
+	FormatOpenMetrics = "openmetrics"
+)
+
+func main() {
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer cancel()
+	if err := run(ctx, os.Args[1:], os.Stdout); err != nil {
+		fmt.Fprintf(os.Stderr, "export: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(ctx context.Context, args []string, stdout io.Writer) error {
+	f := flag.NewFlagSet("export", flag.ContinueOnError)
+	fFormat := f.String("format", FormatCSV, "output format: csv, benchstat or openmetrics")
+	fOut := f.String("o", "", "output file, defaults to stdout")
+	fPush := f.String("push", "", "URL to POST OpenMetrics output to instead of writing it")
+	if err := f.Parse(args); err != nil {
+		return err
+	}
+	if f.NArg() < 1 {
+		return fmt.Errorf("missing archive files")
+	}
+	if *fPush != "" && *fFormat != FormatOpenMetrics {
+		return fmt.Errorf("-push requires -format %s", FormatOpenMetrics)
+	}
+
+	archives := make([]*results.Archive, f.NArg())
+	for i, p := range f.Args() {
+		a, err := results.ReadFile(p)
+		if err != nil {
+			return err
+		}
+		archives[i] = a
+	}
+
+	var b bytes.Buffer
+	switch *fFormat {
+	case FormatCSV:
+		if err := results.WriteCSV(&b, archives...); err != nil {
+			return err
+		}
+	case FormatBenchstat:
+		// benchstat compares files, not configurations within one file.
+		if len(archives) > 1 {
+			return fmt.Errorf("benchstat format requires exactly one archive, got %d",
+				len(archives))
+		}
+		if err := results.WriteBenchstat(&b, archives[0]); err != nil {
+			return err
+		}
+	case FormatOpenMetrics:
+		if err := results.WriteOpenMetrics(&b, archives...); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("unknown format %q", *fFormat)
+	}
+
+	if *fPush != "" {
+		return push(ctx, *fPush, &b)
+	}
+	if *fOut != "" {
+		return os.WriteFile(*fOut, b.Bytes(), 0o644)
+	}
+	_, err := stdout.Write(b.Bytes())
+	return err
+}
+
+func push(ctx context.Context, url string, body io.Reader) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", results.OpenMetricsContentType)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("pushing metrics: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("pushing metrics: %s: %s", resp.Status, bytes.TrimSpace(msg))
+	}
+	return nil
+}
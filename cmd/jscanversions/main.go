@@ -0,0 +1,271 @@
+// Command jscanversions vendors released jscan versions and optionally a
+// local checkout under distinct import paths in the jscanversions package
+// and registers each of them as a separate library in all suites.
+// Previously generated versions are removed.
+//
+//	JSCANBENCH_JSCAN_LOCAL=../jscan go run ./cmd/jscanversions v2.0.0 v2.0.2
+//	go test -bench . -benchmem ./...
+//
+// Running it without arguments and without a local checkout
+// resets the registry to the pinned version only.
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"go/format"
+	"io/fs"
+	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"text/template"
+
+	"github.com/romshark/jscan-benchmark/jscanversions"
+)
+
+// Module is the module path of jscan v2.
+const Module = "github.com/romshark/jscan/v2"
+
+func main() {
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer cancel()
+	if err := run(ctx, os.Args[1:]); err != nil {
+		fmt.Fprintf(os.Stderr, "jscanversions: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// version is a jscan version to vendor.
+type version struct {
+	Ident   string // Package directory and Go identifier suffix
+	Name    string // Library name
+	Version string
+	Import  string // Import path of the vendored copy
+	src     string
+}
+
+func run(ctx context.Context, args []string) error {
+	f := flag.NewFlagSet("jscanversions", flag.ContinueOnError)
+	fDir := f.String("dir", "jscanversions", "directory of the jscanversions package")
+	if err := f.Parse(args); err != nil {
+		return err
+	}
+
+	modPath, err := goOutput(ctx, "list", "-m")
+	if err != nil {
+		return err
+	}
+	dir, err := filepath.Abs(*fDir)
+	if err != nil {
+		return err
+	}
+	pkg, err := goOutput(ctx, "list", "-f", "{{.ImportPath}}", dir)
+	if err != nil {
+		return err
+	}
+	if !strings.HasPrefix(pkg, modPath) {
+		return fmt.Errorf("%s isn't part of module %s", dir, modPath)
+	}
+
+	var versions []version
+	for _, v := range f.Args() {
+		src, err := download(ctx, v)
+		if err != nil {
+			return err
+		}
+		ident := "v" + strings.NewReplacer(".", "_", "-", "_", "+", "_").
+			Replace(strings.TrimPrefix(v, "v"))
+		versions = append(versions, version{
+			Ident: ident, Name: "jscan_" + v, Version: v, src: src,
+		})
+	}
+	if local := os.Getenv(jscanversions.EnvLocal); local != "" {
+		if err := checkLocal(local); err != nil {
+			return err
+		}
+		versions = append(versions, version{
+			Ident: "local", Name: "jscan_local", Version: "(devel)", src: local,
+		})
+	}
+
+	if err := clean(dir); err != nil {
+		return err
+	}
+	for _, v := range versions {
+		v.Import = pkg + "/" + v.Ident
+		if err := vendor(v.src, filepath.Join(dir, v.Ident), v.Import); err != nil {
+			return fmt.Errorf("vendoring %s: %w", v.Name, err)
+		}
+		if err := writeRegistration(dir, v); err != nil {
+			return err
+		}
+		fmt.Fprintf(os.Stderr, "registered %s (%s)\n", v.Name, v.Import)
+	}
+	return nil
+}
+
+func goOutput(ctx context.Context, args ...string) (string, error) {
+	c := exec.CommandContext(ctx, "go", args...)
+	var stderr bytes.Buffer
+	c.Stderr = &stderr
+	b, err := c.Output()
+	if err != nil {
+		return "", fmt.Errorf("running go %s: %w: %s",
+			strings.Join(args, " "), err, bytes.TrimSpace(stderr.Bytes()))
+	}
+	return string(bytes.TrimSpace(b)), nil
+}
+
+// download downloads version v of jscan to the module cache
+// and returns its directory.
+func download(ctx context.Context, v string) (string, error) {
+	c := exec.CommandContext(ctx, "go", "mod", "download", "-json", Module+"@"+v)
+	b, _ := c.Output()
+	var m struct{ Dir, Error string }
+	if err := json.Unmarshal(b, &m); err != nil {
+		return "", fmt.Errorf("downloading %s@%s: %w", Module, v, err)
+	}
+	if m.Error != "" {
+		return "", fmt.Errorf("downloading %s@%s: %s", Module, v, m.Error)
+	}
+	return m.Dir, nil
+}
+
+// checkLocal returns an error if dir isn't a checkout of jscan v2.
+func checkLocal(dir string) error {
+	b, err := os.ReadFile(filepath.Join(dir, "go.mod"))
+	if err != nil {
+		return fmt.Errorf("%s: %w", jscanversions.EnvLocal, err)
+	}
+	for _, l := range strings.Split(string(b), "\n") {
+		if f := strings.Fields(l); len(f) == 2 && f[0] == "module" {
+			if f[1] != Module {
+				return fmt.Errorf("%s: %s is module %s, expected %s",
+					jscanversions.EnvLocal, dir, f[1], Module)
+			}
+			return nil
+		}
+	}
+	return fmt.Errorf("%s: %s has no module directive", jscanversions.EnvLocal, dir)
+}
+
+// clean removes all previously generated versions from dir.
+func clean(dir string) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+	for _, e := range entries {
+		p := filepath.Join(dir, e.Name())
+		switch {
+		case e.IsDir():
+			err = os.RemoveAll(p)
+		case strings.HasPrefix(e.Name(), "version_"):
+			err = os.Remove(p)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// vendor copies the non-test Go sources of the jscan module in src to dst
+// rewriting imports of the module to importPath.
+func vendor(src, dst, importPath string) error {
+	rewrite := strings.NewReplacer(`"`+Module+`"`, `"`+importPath+`"`,
+		`"`+Module+`/`, `"`+importPath+`/`)
+	return filepath.WalkDir(src, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(src, p)
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			n := d.Name()
+			if rel != "." && (n == "testdata" || strings.HasPrefix(n, ".") ||
+				strings.HasPrefix(n, "_") || exists(filepath.Join(p, "go.mod"))) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if rel != "LICENSE" &&
+			(!strings.HasSuffix(p, ".go") || strings.HasSuffix(p, "_test.go")) {
+			return nil
+		}
+		b, err := os.ReadFile(p)
+		if err != nil {
+			return err
+		}
+		if strings.HasSuffix(p, ".go") {
+			b = []byte(rewrite.Replace(string(b)))
+		}
+		out := filepath.Join(dst, rel)
+		if err := os.MkdirAll(filepath.Dir(out), 0o755); err != nil {
+			return err
+		}
+		return os.WriteFile(out, b, 0o644)
+	})
+}
+
+func exists(p string) bool {
+	_, err := os.Stat(p)
+	return err == nil
+}
+
+var registration = template.Must(template.New("").Parse(`// Code generated by cmd/jscanversions. DO NOT EDIT.
+
+package jscanversions
+
+import (
+	jscan "{{.Import}}"
+
+	pinned "github.com/romshark/jscan/v2"
+)
+
+type iterator_{{.Ident}} struct{ *jscan.Iterator[[]byte] }
+
+func (i iterator_{{.Ident}}) ValueType() pinned.ValueType {
+	return pinned.ValueType(i.Iterator.ValueType())
+}
+
+func init() {
+	register(Version{
+		Name:    {{printf "%q" .Name}},
+		Version: {{printf "%q" .Version}},
+		NewValidator: func(preallocStackFrames int) func(src []byte) bool {
+			return jscan.NewValidator[[]byte](preallocStackFrames).Valid
+		},
+		NewParser: func(preallocStackFrames int) ScanFunc {
+			p := jscan.NewParser[[]byte](preallocStackFrames)
+			return func(src []byte, fn func(Iterator) (err bool)) error {
+				if err := p.Scan(src, func(i *jscan.Iterator[[]byte]) (err bool) {
+					return fn(iterator_{{.Ident}}{i})
+				}); err.IsErr() {
+					return err
+				}
+				return nil
+			}
+		},
+	})
+}
+`))
+
+func writeRegistration(dir string, v version) error {
+	var b bytes.Buffer
+	if err := registration.Execute(&b, v); err != nil {
+		return err
+	}
+	src, err := format.Source(b.Bytes())
+	if err != nil {
+		return fmt.Errorf("formatting registration of %s: %w", v.Name, err)
+	}
+	return os.WriteFile(filepath.Join(dir, "version_"+v.Ident+".go"), src, 0o644)
+}
@@ -0,0 +1,290 @@
+// Command pgo measures the effect of profile-guided optimization.
+// It collects a CPU profile of every case of each suite, merges them into
+// a profile per suite, builds the test binaries with and without -pgo and
+// runs both builds interleaved. The report lists the PGO speedup per
+// library and case and the leaderboards of both builds.
+//
+//	go run ./cmd/pgo -count 6 -o pgo
+//
+// An existing profile, such as the default.pgo of a service,
+// can be used for all suites instead using -profile.
+package main
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"regexp"
+	"strconv"
+
+	"github.com/romshark/jscan-benchmark/preflight"
+	"github.com/romshark/jscan-benchmark/results"
+	"github.com/romshark/jscan-benchmark/runner"
+)
+
+func main() {
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer cancel()
+	if err := run(ctx, os.Args[1:]); err != nil {
+		fmt.Fprintf(os.Stderr, "pgo: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(ctx context.Context, args []string) error {
+	hostname, _ := os.Hostname()
+
+	f := flag.NewFlagSet("pgo", flag.ContinueOnError)
+	fOut := f.String("o", "pgo", "output directory")
+	fFilter := f.String("filter", "",
+		"regular expression matched against suite/input/library")
+	fCount := f.Int("count", 6, "number of samples per case and build")
+	fBenchtime := f.String("benchtime", "1s", "benchmark time per sample")
+	fProfileBenchtime := f.String("profile-benchtime", "1s",
+		"benchmark time per case when collecting profiles")
+	fProfile := f.String("profile", "",
+		"CPU profile to build all suites with instead of collecting profiles")
+	fCPUs := f.String("cpus", "", "CPUs to pin benchmarks to (e.g. 2,3 or 0-3)")
+	fMachine := f.String("machine", hostname, "name of the machine")
+	fBaseline := f.String("baseline", results.DefaultBaseline,
+		"library the leaderboards are relative to")
+	fPreflight := f.String("preflight", preflight.GateWarn,
+		"host checks before running: off, warn or fail")
+	if err := f.Parse(args); err != nil {
+		return err
+	}
+	if *fCount < 1 {
+		return fmt.Errorf("invalid count %d", *fCount)
+	}
+	filter, err := regexp.Compile(*fFilter)
+	if err != nil {
+		return fmt.Errorf("parsing filter: %w", err)
+	}
+	cpus, err := runner.ParseCPUs(*fCPUs)
+	if err != nil {
+		return err
+	}
+	if err := preflight.Gate(os.Stderr, *fPreflight, preflight.Config{}); err != nil {
+		return err
+	}
+
+	out, err := filepath.Abs(*fOut)
+	if err != nil {
+		return err
+	}
+	profile := *fProfile
+	if profile != "" {
+		if profile, err = filepath.Abs(profile); err != nil {
+			return err
+		}
+	}
+
+	patterns := f.Args()
+	if len(patterns) < 1 {
+		patterns = []string{"./..."}
+	}
+	suites, err := runner.Suites(ctx, patterns...)
+	if err != nil {
+		return err
+	}
+
+	var base, pgo results.Set
+	// Failed cases are reported but don't stop the run.
+	failed := map[results.Key]bool{}
+	for _, s := range suites {
+		s.CPUs = cpus
+		if err := s.Compile(ctx, filepath.Join(out, "bin", "default")); err != nil {
+			return err
+		}
+		all, err := s.Cases(ctx)
+		if err != nil {
+			return err
+		}
+		var cases []runner.Case
+		for _, c := range all {
+			if filter.MatchString(c.Key.String()) {
+				cases = append(cases, c)
+			}
+		}
+		if len(cases) < 1 {
+			continue
+		}
+
+		p := profile
+		if p == "" {
+			if p, err = collectProfile(
+				ctx, s, cases, out, *fProfileBenchtime, &base, failed,
+			); err != nil {
+				return err
+			}
+			if p == "" {
+				continue // All cases failed
+			}
+		}
+		s2 := *s
+		if err := s2.Compile(ctx, filepath.Join(out, "bin", "pgo"), "-pgo="+p); err != nil {
+			return err
+		}
+
+		for round := 0; round < *fCount; round++ {
+			for _, c := range cases {
+				if failed[c.Key] {
+					continue
+				}
+				pc := c
+				pc.Suite = &s2
+				// Alternate the build running first to spread out drift.
+				builds := []struct {
+					c   runner.Case
+					set *results.Set
+				}{{c, &base}, {pc, &pgo}}
+				if round%2 == 1 {
+					builds[0], builds[1] = builds[1], builds[0]
+				}
+				for _, b := range builds {
+					if err := sample(ctx, b.c, *fBenchtime, b.set, failed); err != nil {
+						return err
+					}
+				}
+				if failed[c.Key] {
+					continue
+				}
+				fmt.Fprintf(os.Stderr, "%s #%d\n", c.Key, round+1)
+			}
+		}
+		// The versions selected at build time take precedence
+		// over those reported by the test binaries.
+		base.AddVersions(s.Versions)
+		pgo.AddVersions(s2.Versions)
+	}
+	if len(base.Results) < 1 && len(failed) < 1 {
+		return fmt.Errorf("no cases matching %q", *fFilter)
+	}
+
+	for _, a := range []struct {
+		name string
+		set  results.Set
+	}{{"default", base}, {"pgo", pgo}} {
+		ar := &results.Archive{Machine: *fMachine + " (" + a.name + ")", Set: a.set}
+		if err := ar.WriteFile(filepath.Join(out, a.name+".json")); err != nil {
+			return err
+		}
+	}
+
+	var r bytes.Buffer
+	r.WriteString("# PGO\n\n")
+	var failures results.Set
+	failures.AddFailures(base.Failures)
+	failures.AddFailures(pgo.Failures)
+	if err := results.WriteFailures(&r, failures.Failures); err != nil {
+		return err
+	}
+	if err := results.WriteComparison(
+		&r, "default", "pgo", results.Compare(&base, &pgo),
+	); err != nil {
+		return err
+	}
+	sc := results.Scoring{Baseline: *fBaseline}
+	for _, x := range []struct {
+		title string
+		set   *results.Set
+	}{{"Default build", &base}, {"PGO build", &pgo}} {
+		fmt.Fprintf(&r, "## %s\n\n", x.title)
+		if err := results.WriteLeaderboard(&r, sc, x.set.Suites(), sc.Scores(x.set)); err != nil {
+			return err
+		}
+	}
+	if err := os.WriteFile(filepath.Join(out, "report.md"), r.Bytes(), 0o644); err != nil {
+		return err
+	}
+	if _, err := os.Stdout.Write(r.Bytes()); err != nil {
+		return err
+	}
+	if n := len(failures.Failures); n > 0 {
+		return fmt.Errorf("%d failed cases, reported in %s",
+			n, filepath.Join(out, "report.md"))
+	}
+	return nil
+}
+
+// collectProfile profiles every case of the suite and returns the path
+// of the merged profile, or an empty path if all cases failed.
+// Failed cases are added to set and marked in failed.
+func collectProfile(
+	ctx context.Context, s *runner.Suite, cases []runner.Case, out, benchtime string,
+	set *results.Set, failed map[results.Key]bool,
+) (string, error) {
+	dir := filepath.Join(out, "profiles", s.Name())
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", err
+	}
+	var profiles []string
+	for i, c := range cases {
+		fmt.Fprintf(os.Stderr, "profiling %s\n", c.Key)
+		p := filepath.Join(dir, strconv.Itoa(i)+".pprof")
+		res, err := c.Results(ctx, nil,
+			"-test.benchtime", benchtime,
+			"-test.cpuprofile", p,
+		)
+		if err != nil {
+			return "", fmt.Errorf("profiling %s: %w", c.Key, err)
+		}
+		if addFailures(set, res, failed) {
+			continue
+		}
+		profiles = append(profiles, p)
+	}
+	if len(profiles) < 1 {
+		return "", nil
+	}
+	merged := filepath.Join(out, "profiles", s.Name()+".pprof")
+	args := append([]string{"tool", "pprof", "-proto", "-output", merged}, profiles...)
+	c := exec.CommandContext(ctx, "go", args...)
+	var stderr bytes.Buffer
+	c.Stderr = &stderr
+	if err := c.Run(); err != nil {
+		return "", fmt.Errorf("merging profiles of %s: %w: %s", s.Name(), err, stderr.String())
+	}
+	return merged, nil
+}
+
+// sample runs c once and adds the results to set.
+// A failed case is added to set and marked in failed.
+func sample(
+	ctx context.Context, c runner.Case, benchtime string,
+	set *results.Set, failed map[results.Key]bool,
+) error {
+	s, err := c.Results(ctx, nil,
+		"-test.benchtime", benchtime, "-test.benchmem", "-test.count", "1")
+	if err != nil {
+		return err
+	}
+	if addFailures(set, s, failed) {
+		return nil
+	}
+	if len(s.Results) < 1 {
+		return fmt.Errorf("no results for %s", c.Key)
+	}
+	if set.CPU == "" {
+		set.Goos, set.Goarch, set.CPU = s.Goos, s.Goarch, s.CPU
+	}
+	set.Results = append(set.Results, s.Results...)
+	set.AddVersions(s.Versions)
+	return nil
+}
+
+// addFailures adds the failures of the run res to set, marks their cases
+// in failed and returns whether there were any.
+func addFailures(set, res *results.Set, failed map[results.Key]bool) bool {
+	for _, f := range res.Failures {
+		fmt.Fprintf(os.Stderr, "%s: FAIL: %s\n", f.Key, f.Message)
+		failed[f.Key] = true
+	}
+	set.AddFailures(res.Failures)
+	return len(res.Failures) > 0
+}
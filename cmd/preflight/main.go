@@ -0,0 +1,35 @@
+// Command preflight checks the benchmark host for common sources of noise
+// such as CPU frequency scaling, turbo boost, system load, virtualization,
+// low memory and swapping. Exits with code 1 if there are any warnings.
+//
+//	go run ./cmd/preflight
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/romshark/jscan-benchmark/preflight"
+)
+
+func main() {
+	fMaxLoad := flag.Float64("max-load", preflight.DefaultMaxLoad,
+		"maximum acceptable 1-minute load average")
+	fMinMem := flag.Uint64("min-memory", preflight.DefaultMinMemory,
+		"minimum available memory in bytes")
+	flag.Parse()
+
+	f := preflight.Run(preflight.Config{
+		MaxLoad:   *fMaxLoad,
+		MinMemory: *fMinMem,
+	})
+	if err := preflight.Write(os.Stdout, f); err != nil {
+		fmt.Fprintf(os.Stderr, "preflight: %v\n", err)
+		os.Exit(1)
+	}
+	if w := preflight.Warnings(f); len(w) > 0 {
+		fmt.Fprintf(os.Stderr, "preflight: %d warnings\n", len(w))
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,195 @@
+// Command profile runs each benchmark case in isolation with CPU and heap
+// profiling enabled, stores the profiles as <suite>/<input>/<library>.{cpu,mem}.pprof
+// and writes a summary of the top-N hotspots per case to summary.md.
+// Profiling starts after the input of the case is loaded, see test.EnvProfile.
+// The allocations made before are stored in <library>.mem.base.pprof
+// and subtracted from the allocation hotspots.
+//
+//	go run ./cmd/profile -filter 'validation/array_str_1024_639k/' -o profiles
+package main
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/romshark/jscan-benchmark/preflight"
+	"github.com/romshark/jscan-benchmark/results"
+	"github.com/romshark/jscan-benchmark/runner"
+	"github.com/romshark/jscan-benchmark/test"
+)
+
+func main() {
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer cancel()
+	if err := run(ctx, os.Args[1:]); err != nil {
+		fmt.Fprintf(os.Stderr, "profile: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(ctx context.Context, args []string) error {
+	f := flag.NewFlagSet("profile", flag.ContinueOnError)
+	fOut := f.String("o", "profiles", "output directory")
+	fFilter := f.String("filter", "",
+		"regular expression matched against suite/input/library")
+	fBenchtime := f.String("benchtime", "1s", "benchmark time per case")
+	fTop := f.Int("top", 10, "number of hotspots per case in the summary")
+	fMemRate := f.Int("memprofilerate", 0,
+		"heap profiling rate in bytes (0 uses the runtime default)")
+	fPreflight := f.String("preflight", preflight.GateWarn,
+		"host checks before running: off, warn or fail")
+	if err := f.Parse(args); err != nil {
+		return err
+	}
+	if err := preflight.Gate(os.Stderr, *fPreflight, preflight.Config{}); err != nil {
+		return err
+	}
+	filter, err := regexp.Compile(*fFilter)
+	if err != nil {
+		return fmt.Errorf("parsing filter: %w", err)
+	}
+
+	patterns := f.Args()
+	if len(patterns) < 1 {
+		patterns = []string{"./..."}
+	}
+	suites, err := runner.Suites(ctx, patterns...)
+	if err != nil {
+		return err
+	}
+
+	out, err := filepath.Abs(*fOut)
+	if err != nil {
+		return err
+	}
+	binDir := filepath.Join(out, "bin")
+	if err := os.MkdirAll(binDir, 0o755); err != nil {
+		return err
+	}
+
+	var summary bytes.Buffer
+	summary.WriteString("# Profiles\n\n")
+	// Failed cases are reported but don't stop the run.
+	var failures results.Set
+	for _, s := range suites {
+		if err := s.Compile(ctx, binDir); err != nil {
+			return err
+		}
+		cases, err := s.Cases(ctx)
+		if err != nil {
+			return err
+		}
+		for _, c := range cases {
+			if !filter.MatchString(c.Key.String()) {
+				continue
+			}
+			fmt.Fprintf(os.Stderr, "profiling %s\n", c.Key)
+			f, err := profile(ctx, c, out, *fBenchtime, *fMemRate, *fTop, &summary)
+			if err != nil {
+				return fmt.Errorf("profiling %s: %w", c.Key, err)
+			}
+			for _, f := range f {
+				fmt.Fprintf(os.Stderr, "%s: FAIL: %s\n", f.Key, f.Message)
+			}
+			failures.AddFailures(f)
+		}
+	}
+	if err := results.WriteFailures(&summary, failures.Failures); err != nil {
+		return err
+	}
+	p := filepath.Join(out, "summary.md")
+	if err := os.WriteFile(p, summary.Bytes(), 0o644); err != nil {
+		return err
+	}
+	if n := len(failures.Failures); n > 0 {
+		return fmt.Errorf("%d failed cases, reported in %s", n, p)
+	}
+	return nil
+}
+
+// profile profiles c and adds its hotspots to summary.
+// A failed case isn't profiled, its failures are returned instead.
+func profile(
+	ctx context.Context, c runner.Case, out, benchtime string,
+	memRate, top int, summary *bytes.Buffer,
+) ([]results.Failure, error) {
+	base := filepath.Join(out, c.Suite.Name(), c.Input, c.Library)
+	if err := os.MkdirAll(filepath.Dir(base), 0o755); err != nil {
+		return nil, err
+	}
+	cpu, mem, memBase := base+".cpu.pprof", base+".mem.pprof", base+".mem.base.pprof"
+	flags := []string{"-test.benchtime", benchtime, "-test.benchmem"}
+	if memRate > 0 {
+		flags = append(flags, "-test.memprofilerate", strconv.Itoa(memRate))
+	}
+	res, err := c.RunEnv(ctx, []string{test.EnvProfile + "=" + base}, flags...)
+	if err != nil {
+		set, errParse := results.Parse(bytes.NewReader(res))
+		if errParse != nil || len(set.Failures) < 1 {
+			return nil, err
+		}
+		return []results.Failure{{Key: c.Key, Message: set.Failures[0].Message}}, nil
+	}
+
+	cpuTop, err := hotspots(ctx, c.Suite.Binary, cpu, top)
+	if err != nil {
+		return nil, err
+	}
+	memTop, err := hotspots(ctx, c.Suite.Binary, mem, top,
+		"-sample_index=alloc_space", "-base", memBase)
+	if err != nil {
+		return nil, err
+	}
+
+	fmt.Fprintf(summary, "## %s\n\n```\n%s\n```\n\n", c.Key, resultLine(res))
+	fmt.Fprintf(summary, "CPU (`%s`):\n\n```\n%s```\n\n", rel(out, cpu), cpuTop)
+	fmt.Fprintf(summary, "Allocations (`%s`):\n\n```\n%s```\n\n", rel(out, mem), memTop)
+	return nil, os.WriteFile(base+".top.txt", []byte(cpuTop+"\n"+memTop), 0o644)
+}
+
+// hotspots returns the top n entries of the profile.
+func hotspots(
+	ctx context.Context, binary, profile string, n int, options ...string,
+) (string, error) {
+	args := append([]string{"tool", "pprof", "-top", "-nodecount", strconv.Itoa(n)}, options...)
+	args = append(args, binary, profile)
+	c := exec.CommandContext(ctx, "go", args...)
+	var stderr bytes.Buffer
+	c.Stderr = &stderr
+	b, err := c.Output()
+	if err != nil {
+		return "", fmt.Errorf("running pprof: %w: %s", err, stderr.String())
+	}
+	// Skip the preamble before the table header.
+	s := string(b)
+	if i := strings.Index(s, "      flat"); i != -1 {
+		s = s[i:]
+	}
+	return s, nil
+}
+
+// resultLine returns the benchmark result line from the test binary output.
+func resultLine(out []byte) string {
+	for _, l := range strings.Split(string(out), "\n") {
+		if strings.HasPrefix(l, "Benchmark") && strings.Contains(l, "ns/op") {
+			return strings.TrimSpace(l)
+		}
+	}
+	return ""
+}
+
+func rel(base, p string) string {
+	if r, err := filepath.Rel(base, p); err == nil {
+		return r
+	}
+	return p
+}
@@ -0,0 +1,141 @@
+// Command report renders the output of `go test -bench` as markdown tables
+// followed by a leaderboard of geometric-mean speedups.
+//
+//	go test -bench . -benchmem ./... | go run ./cmd/report
+//	go run ./cmd/report -baseline jscan -weights large=2,tiny=0.5 bench_output.txt
+//	go run ./cmd/report results.json
+//	go run ./cmd/report -regress base.json -thresholds ns/op=0.1 new.json
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/romshark/jscan-benchmark/results"
+	"github.com/romshark/jscan-benchmark/test"
+)
+
+func main() {
+	if err := run(os.Args[1:], os.Stdin, os.Stdout); err != nil {
+		fmt.Fprintf(os.Stderr, "report: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(args []string, stdin io.Reader, w io.Writer) error {
+	f := flag.NewFlagSet("report", flag.ContinueOnError)
+	fBaseline := f.String("baseline", results.DefaultBaseline,
+		"library to compute speedups against, empty to disable the leaderboard")
+	fWeights := f.String("weights", "",
+		"comma-separated input category weights (e.g. large=2,tiny=0.5)")
+	fMaxCV := f.Float64("max-cv", results.DefaultMaxCV,
+		"maximum coefficient of variation of a trustworthy case")
+	fRegress := f.String("regress", "",
+		"archive to check for regressions against, fails if any threshold is exceeded")
+	fThresholds := f.String("thresholds", "",
+		"comma-separated maximum relative regressions per unit overriding the defaults "+
+			"(e.g. ns/op=0.1,peak-heap-B=0.2)")
+	if err := f.Parse(args); err != nil {
+		return err
+	}
+
+	thresholds, err := results.ParseThresholds(*fThresholds)
+	if err != nil {
+		return err
+	}
+	weights, err := parseWeights(*fWeights)
+	if err != nil {
+		return err
+	}
+
+	s, err := readSet(f.Args(), stdin)
+	if err != nil {
+		return err
+	}
+	if err := results.WriteMarkdown(w, s, results.Diagnostics{MaxCV: *fMaxCV}); err != nil {
+		return err
+	}
+	if *fBaseline != "" {
+		sc := results.Scoring{
+			Baseline:   *fBaseline,
+			Weights:    weights,
+			Categories: categories,
+		}
+		if err := results.WriteLeaderboard(w, sc, s.Suites(), sc.Scores(s)); err != nil {
+			return err
+		}
+	}
+	if *fRegress == "" {
+		return nil
+	}
+	base, err := results.ReadFile(*fRegress)
+	if err != nil {
+		return err
+	}
+	r := results.Regressions(&base.Set, s, thresholds)
+	if err := results.WriteRegressions(w, r); err != nil {
+		return err
+	}
+	if len(r) > 0 {
+		return fmt.Errorf("%d regressions against %s", len(r), *fRegress)
+	}
+	return nil
+}
+
+// categories returns the manifest categories of the input of c,
+// or its size category if the input isn't part of the corpus.
+func categories(c results.Case) []string {
+	if in, ok := test.Lookup(c.Input); ok {
+		return in.Categories
+	}
+	if b, ok := c.BytesPerOp(); ok {
+		return []string{test.SizeCategory(int(b))}
+	}
+	return nil
+}
+
+func parseWeights(s string) (map[string]float64, error) {
+	if s == "" {
+		return nil, nil
+	}
+	m := map[string]float64{}
+	for _, p := range strings.Split(s, ",") {
+		k, v, ok := strings.Cut(p, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid weight %q, expected category=weight", p)
+		}
+		f, err := strconv.ParseFloat(v, 64)
+		if err != nil || f < 0 {
+			return nil, fmt.Errorf("invalid weight %q for category %q", v, k)
+		}
+		m[strings.TrimSpace(k)] = f
+	}
+	return m, nil
+}
+
+// readSet reads and merges the results of all files,
+// or the benchmark output from stdin if files is empty.
+func readSet(files []string, stdin io.Reader) (*results.Set, error) {
+	if len(files) < 1 {
+		return results.Parse(stdin)
+	}
+	var s *results.Set
+	for _, f := range files {
+		a, err := results.ReadFile(f)
+		if err != nil {
+			return nil, err
+		}
+		if s == nil {
+			s = &a.Set
+		} else {
+			s.Results = append(s.Results, a.Results...)
+			s.AddVersions(a.Versions)
+			s.AddFailures(a.Failures)
+		}
+	}
+	return s, nil
+}
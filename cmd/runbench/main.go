@@ -0,0 +1,298 @@
+// Command runbench runs all benchmark cases in isolated processes,
+// interleaving libraries to spread out drift, optionally pinned to a set
+// of CPUs. Progress is checkpointed to disk after every case so that an
+// interrupted run can be resumed. A results archive is written at the end.
+// Failed cases don't stop the run, they're listed in the archive
+// and make the command fail after archiving.
+//
+//	go run ./cmd/runbench -count 12 -cpus 2,3 -o results.json
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/signal"
+	"regexp"
+	"strconv"
+	"time"
+
+	"github.com/romshark/jscan-benchmark/preflight"
+	"github.com/romshark/jscan-benchmark/results"
+	"github.com/romshark/jscan-benchmark/runner"
+	"github.com/romshark/jscan-benchmark/test"
+)
+
+func main() {
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer cancel()
+	if err := run(ctx, os.Args[1:]); err != nil {
+		fmt.Fprintf(os.Stderr, "runbench: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// config is the part of the configuration that must not change
+// when resuming a run.
+type config struct {
+	Patterns  []string `json:"patterns"`
+	Filter    string   `json:"filter"`
+	Count     int      `json:"count"`
+	Benchtime string   `json:"benchtime"`
+	CPUs      []int    `json:"cpus"`
+	PeakHeap  bool     `json:"peakHeap,omitempty"`
+}
+
+// checkpoint is the progress of a run persisted to disk.
+type checkpoint struct {
+	Config  config      `json:"config"`
+	Started time.Time   `json:"started"`
+	Done    []string    `json:"done"`
+	Set     results.Set `json:"set"`
+}
+
+func run(ctx context.Context, args []string) error {
+	hostname, _ := os.Hostname()
+
+	f := flag.NewFlagSet("runbench", flag.ContinueOnError)
+	fCount := f.Int("count", 6, "number of samples per case")
+	fBenchtime := f.String("benchtime", "1s", "benchmark time per sample")
+	fFilter := f.String("filter", "",
+		"regular expression matched against suite/input/library")
+	fCPUs := f.String("cpus", "", "CPUs to pin benchmarks to (e.g. 2,3 or 0-3)")
+	fPeakHeap := f.Bool("peak-heap", false,
+		"sample the heap in-use to report its peak, perturbs timing")
+	fCheckpoint := f.String("checkpoint", "runbench.checkpoint.json", "checkpoint file")
+	fRestart := f.Bool("restart", false, "discard an existing checkpoint")
+	fOut := f.String("o", "results.json", "results archive file")
+	fMachine := f.String("machine", hostname, "name of the machine")
+	fMaxCV := f.Float64("max-cv", results.DefaultMaxCV,
+		"maximum coefficient of variation of a trustworthy case")
+	fForce := f.Bool("force", false,
+		"archive even if results are noisy or have insufficient samples")
+	fPreflight := f.String("preflight", preflight.GateWarn,
+		"host checks before running: off, warn or fail")
+	if err := f.Parse(args); err != nil {
+		return err
+	}
+	if *fCount < 1 {
+		return fmt.Errorf("invalid count %d", *fCount)
+	}
+	filter, err := regexp.Compile(*fFilter)
+	if err != nil {
+		return fmt.Errorf("parsing filter: %w", err)
+	}
+	cpus, err := runner.ParseCPUs(*fCPUs)
+	if err != nil {
+		return err
+	}
+	conf := config{
+		Patterns:  f.Args(),
+		Filter:    *fFilter,
+		Count:     *fCount,
+		Benchtime: *fBenchtime,
+		CPUs:      cpus,
+		PeakHeap:  *fPeakHeap,
+	}
+	if len(conf.Patterns) < 1 {
+		conf.Patterns = []string{"./..."}
+	}
+
+	cp, err := loadCheckpoint(*fCheckpoint, conf, *fRestart)
+	if err != nil {
+		return err
+	}
+	if len(cp.Done) > 0 {
+		fmt.Fprintf(os.Stderr, "resuming %d completed samples from %s\n",
+			len(cp.Done), *fCheckpoint)
+	}
+
+	if err := preflight.Gate(os.Stderr, *fPreflight, preflight.Config{}); err != nil {
+		return err
+	}
+
+	binDir, err := os.MkdirTemp("", "runbench-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(binDir)
+
+	suites, err := runner.Suites(ctx, conf.Patterns...)
+	if err != nil {
+		return err
+	}
+	var cases []runner.Case
+	for _, s := range suites {
+		s.CPUs = cpus
+		if err := s.Compile(ctx, binDir); err != nil {
+			return err
+		}
+		c, err := s.Cases(ctx)
+		if err != nil {
+			return err
+		}
+		for _, c := range c {
+			if filter.MatchString(c.Key.String()) {
+				cases = append(cases, c)
+			}
+		}
+	}
+
+	done := make(map[string]bool, len(cp.Done))
+	for _, id := range cp.Done {
+		done[id] = true
+	}
+	plan := schedule(cases, conf.Count)
+	for i, s := range plan {
+		id := s.id()
+		if done[id] {
+			continue
+		}
+		var env []string
+		if conf.PeakHeap {
+			env = append(env, test.EnvPeakHeap+"=1")
+		}
+		set, err := s.Results(ctx, env,
+			"-test.benchtime", conf.Benchtime,
+			"-test.benchmem",
+			"-test.count", "1",
+		)
+		if err != nil {
+			return err
+		}
+		if len(set.Failures) > 0 {
+			// Failed cases are reported but don't stop the run.
+			cp.Set.AddFailures(set.Failures)
+			cp.Done = append(cp.Done, id)
+			if err := cp.save(*fCheckpoint); err != nil {
+				return err
+			}
+			fmt.Fprintf(os.Stderr, "[%d/%d] %s #%d: FAIL: %s\n",
+				i+1, len(plan), s.Key, s.round+1, set.Failures[0].Message)
+			continue
+		}
+		if len(set.Results) < 1 {
+			return fmt.Errorf("no results for %s", s.Key)
+		}
+		if cp.Set.CPU == "" {
+			cp.Set.Goos, cp.Set.Goarch, cp.Set.CPU = set.Goos, set.Goarch, set.CPU
+		}
+		cp.Set.Results = append(cp.Set.Results, set.Results...)
+		cp.Set.AddVersions(set.Versions)
+		cp.Done = append(cp.Done, id)
+		if err := cp.save(*fCheckpoint); err != nil {
+			return err
+		}
+		fmt.Fprintf(os.Stderr, "[%d/%d] %s #%d: %s ns/op\n",
+			i+1, len(plan), s.Key, s.round+1,
+			results.FormatValue(results.UnitNsPerOp, set.Results[0].Metrics[results.UnitNsPerOp]))
+	}
+
+	d := results.Diagnostics{MaxCV: *fMaxCV}
+	if untrusted := d.Untrusted(&cp.Set); len(untrusted) > 0 {
+		for _, c := range untrusted {
+			fmt.Fprintf(os.Stderr, "%s: %s\n", c.Key, d.Diagnose(c).Verdict(d))
+		}
+		if !*fForce {
+			return fmt.Errorf("%d noisy cases or cases with insufficient samples, "+
+				"rerun with -force to archive anyway (progress is kept in %s)",
+				len(untrusted), *fCheckpoint)
+		}
+	}
+	// The versions selected at build time take precedence
+	// over those reported by the test binaries.
+	for _, s := range suites {
+		cp.Set.AddVersions(s.Versions)
+	}
+	a := &results.Archive{Machine: *fMachine, Date: cp.Started, Set: cp.Set}
+	if err := a.WriteFile(*fOut); err != nil {
+		return err
+	}
+	if err := os.Remove(*fCheckpoint); err != nil {
+		return err
+	}
+	if n := len(cp.Set.Failures); n > 0 {
+		return fmt.Errorf("%d failed cases, archived in %s", n, *fOut)
+	}
+	return nil
+}
+
+// sample is a single run of a case.
+type sample struct {
+	runner.Case
+	round int
+}
+
+func (s sample) id() string { return s.Suite.Name() + "/" + s.Name + "#" + strconv.Itoa(s.round) }
+
+// schedule returns count rounds of all cases. Within each round,
+// the libraries of each input run in an order rotated by the round
+// so that drift is spread evenly across libraries.
+func schedule(cases []runner.Case, count int) []sample {
+	type group struct{ suite, input string }
+	var order []group
+	groups := map[group][]runner.Case{}
+	for _, c := range cases {
+		g := group{c.Suite.Name(), c.Input}
+		if _, ok := groups[g]; !ok {
+			order = append(order, g)
+		}
+		groups[g] = append(groups[g], c)
+	}
+	l := make([]sample, 0, len(cases)*count)
+	for round := 0; round < count; round++ {
+		for _, g := range order {
+			c := groups[g]
+			for i := range c {
+				l = append(l, sample{Case: c[(i+round)%len(c)], round: round})
+			}
+		}
+	}
+	return l
+}
+
+func loadCheckpoint(path string, conf config, restart bool) (*checkpoint, error) {
+	cp := &checkpoint{Config: conf, Started: time.Now().UTC()}
+	if restart {
+		return cp, nil
+	}
+	b, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return cp, nil
+	} else if err != nil {
+		return nil, err
+	}
+	var prev checkpoint
+	if err := json.Unmarshal(b, &prev); err != nil {
+		return nil, fmt.Errorf("decoding checkpoint %q: %w", path, err)
+	}
+	if !equalConfig(prev.Config, conf) {
+		return nil, fmt.Errorf("checkpoint %q was created with a different "+
+			"configuration, use -restart to discard it", path)
+	}
+	return &prev, nil
+}
+
+func equalConfig(a, b config) bool {
+	ja, _ := json.Marshal(a)
+	jb, _ := json.Marshal(b)
+	return bytes.Equal(ja, jb)
+}
+
+// save atomically writes the checkpoint to path.
+func (cp *checkpoint) save(path string) error {
+	b, err := json.Marshal(cp)
+	if err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, b, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
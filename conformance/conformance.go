@@ -0,0 +1,230 @@
+// Package conformance checks the libraries against the JSONTestSuite
+// corpus (https://github.com/nst/JSONTestSuite) in testdata/jsontestsuite
+// and the UTF8 catalog. Files prefixed with y_ must be accepted,
+// files prefixed with n_ must be rejected and files prefixed
+// with i_ may be either.
+package conformance
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Expectation is the expected verdict for a file.
+type Expectation string
+
+// Expectations of the JSONTestSuite file name prefixes.
+const (
+	ExpectAccept Expectation = "y"
+	ExpectReject Expectation = "n"
+	ExpectEither Expectation = "i" // Implementation defined
+)
+
+// File is a file of the corpus.
+type File struct {
+	Name   string
+	Expect Expectation
+	Data   []byte
+}
+
+// ReadDir reads all files of the corpus in dir ordered by name.
+// Files without a known prefix, such as the license, are ignored.
+func ReadDir(dir string) ([]File, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	var files []File
+	for _, e := range entries {
+		prefix, _, ok := strings.Cut(e.Name(), "_")
+		if e.IsDir() || !ok {
+			continue
+		}
+		switch x := Expectation(prefix); x {
+		case ExpectAccept, ExpectReject, ExpectEither:
+			data, err := os.ReadFile(filepath.Join(dir, e.Name()))
+			if err != nil {
+				return nil, err
+			}
+			files = append(files, File{Name: e.Name(), Expect: x, Data: data})
+		}
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no JSONTestSuite files in %s", dir)
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i].Name < files[j].Name })
+	return files, nil
+}
+
+// Outcome is the outcome of checking a file using a library.
+type Outcome string
+
+// Outcomes of a check.
+const (
+	OutcomeAccepted Outcome = "accepted"
+	OutcomeRejected Outcome = "rejected"
+	OutcomeCrash    Outcome = "crash"   // The library panicked
+	OutcomeTimeout  Outcome = "timeout" // The library didn't return in time
+)
+
+// Pass returns whether outcome o meets expectation x.
+// Crashes and timeouts never pass.
+func (x Expectation) Pass(o Outcome) bool {
+	switch o {
+	case OutcomeAccepted:
+		return x != ExpectReject
+	case OutcomeRejected:
+		return x != ExpectAccept
+	}
+	return false
+}
+
+// Library validates inputs using a JSON library.
+type Library struct {
+	Name string
+
+	// New returns a validator, a new one is used for every check.
+	New func() func(src []byte) bool
+}
+
+// Check returns the outcome of validating src using a new validator of l
+// so that a check that timed out doesn't share state with the next one.
+// A library that doesn't return within timeout can't be stopped and
+// keeps running in the background until the process exits,
+// cmd/conformance therefore checks in worker processes exiting
+// after a timeout.
+func (l Library) Check(src []byte, timeout time.Duration) Outcome {
+	c := make(chan Outcome, 1)
+	go func() {
+		defer func() {
+			if recover() != nil {
+				c <- OutcomeCrash
+			}
+		}()
+		if l.New()(src) {
+			c <- OutcomeAccepted
+		} else {
+			c <- OutcomeRejected
+		}
+	}()
+	t := time.NewTimer(timeout)
+	defer t.Stop()
+	select {
+	case o := <-c:
+		return o
+	case <-t.C:
+		return OutcomeTimeout
+	}
+}
+
+// Matrix contains the outcome of every library for every file.
+type Matrix struct {
+	Title     string // Title of the corpus, used in headings
+	Files     []File
+	Libraries []string
+	Outcomes  [][]Outcome // Indexed by file then by library
+}
+
+// Run checks all files of the corpus titled title using all libraries.
+func Run(title string, files []File, libraries []Library, timeout time.Duration) *Matrix {
+	m := &Matrix{Title: title, Files: files, Outcomes: make([][]Outcome, len(files))}
+	for _, l := range libraries {
+		m.Libraries = append(m.Libraries, l.Name)
+	}
+	for i, f := range files {
+		m.Outcomes[i] = make([]Outcome, len(libraries))
+		for j, l := range libraries {
+			m.Outcomes[i][j] = l.Check(f.Data, timeout)
+		}
+	}
+	return m
+}
+
+// Summary counts the outcomes of a library.
+type Summary struct {
+	Library string
+	Pass    int // Accepted y_ and rejected n_ files
+	Fail    int // Rejected y_ and accepted n_ files
+	Crash   int
+	Timeout int
+	Either  int // Accepted i_ files
+}
+
+// Summaries returns the summary of every library.
+func (m *Matrix) Summaries() []Summary {
+	s := make([]Summary, len(m.Libraries))
+	for j, l := range m.Libraries {
+		s[j].Library = l
+		for i, f := range m.Files {
+			switch o := m.Outcomes[i][j]; {
+			case o == OutcomeCrash:
+				s[j].Crash++
+			case o == OutcomeTimeout:
+				s[j].Timeout++
+			case f.Expect == ExpectEither:
+				if o == OutcomeAccepted {
+					s[j].Either++
+				}
+			case f.Expect.Pass(o):
+				s[j].Pass++
+			default:
+				s[j].Fail++
+			}
+		}
+	}
+	return s
+}
+
+// WriteMarkdown writes the summary table followed by the conformance table
+// to w. A cell of the conformance table is a check mark if the library
+// passed a y_ or n_ file and "fail" otherwise, the verdict for i_ files,
+// or "crash" or "timeout".
+func (m *Matrix) WriteMarkdown(w io.Writer) error {
+	var b strings.Builder
+	var either int
+	for _, f := range m.Files {
+		if f.Expect == ExpectEither {
+			either++
+		}
+	}
+	fmt.Fprintf(&b, "### %s summary\n\n"+
+		"|library|pass|fail|crash|timeout|accepted i_|\n|-|-:|-:|-:|-:|-:|\n", m.Title)
+	for _, s := range m.Summaries() {
+		fmt.Fprintf(&b, "|%s|%d/%d|%d|%d|%d|%d/%d|\n",
+			s.Library, s.Pass, len(m.Files)-either,
+			s.Fail, s.Crash, s.Timeout, s.Either, either)
+	}
+
+	fmt.Fprintf(&b, "\n### %s conformance\n\n|file|", m.Title)
+	for _, l := range m.Libraries {
+		fmt.Fprintf(&b, "%s|", l)
+	}
+	b.WriteString("\n|-|")
+	for range m.Libraries {
+		b.WriteString("-|")
+	}
+	b.WriteByte('\n')
+	for i, f := range m.Files {
+		fmt.Fprintf(&b, "|%s|", f.Name)
+		for _, o := range m.Outcomes[i] {
+			switch {
+			case o == OutcomeCrash || o == OutcomeTimeout || f.Expect == ExpectEither:
+				b.WriteString(string(o))
+			case f.Expect.Pass(o):
+				b.WriteString("✓")
+			default:
+				b.WriteString("fail")
+			}
+			b.WriteByte('|')
+		}
+		b.WriteByte('\n')
+	}
+	b.WriteByte('\n')
+	_, err := io.WriteString(w, b.String())
+	return err
+}
@@ -0,0 +1,129 @@
+package conformance_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/romshark/jscan-benchmark/conformance"
+
+	"github.com/stretchr/testify/require"
+)
+
+// jscanRejects are the implementation defined files jscan rejects.
+var jscanRejects = map[string]bool{
+	"i_string_UTF-16LE_with_BOM.json":         true,
+	"i_string_utf16BE_no_BOM.json":            true,
+	"i_string_utf16LE_no_BOM.json":            true,
+	"i_structure_UTF-8_BOM_empty_object.json": true,
+}
+
+// TestJscan catches conformance regressions of the pinned and
+// vendored jscan versions including changed verdicts for i_ files.
+func TestJscan(t *testing.T) {
+	files, err := conformance.ReadDir("../testdata/jsontestsuite")
+	require.NoError(t, err)
+	var libraries []conformance.Library
+	for _, l := range conformance.Libraries {
+		if strings.HasPrefix(l.Name, "jscan") {
+			libraries = append(libraries, l)
+		}
+	}
+	for _, c := range []struct {
+		title string
+		files []conformance.File
+	}{
+		{"JSONTestSuite", files},
+		{"UTF-8", conformance.UTF8},
+	} {
+		t.Run(c.title, func(t *testing.T) {
+			m := conformance.Run(c.title, c.files, libraries, 10*time.Second)
+			for i, f := range m.Files {
+				expect := conformance.OutcomeAccepted
+				if f.Expect == conformance.ExpectReject || jscanRejects[f.Name] {
+					expect = conformance.OutcomeRejected
+				}
+				for j, o := range m.Outcomes[i] {
+					require.Equal(t, expect, o, "%s: %s", m.Libraries[j], f.Name)
+				}
+			}
+		})
+	}
+}
+
+// TestUTF8 checks that the only defect of the UTF-8 catalog inputs
+// is the one they are named after.
+func TestUTF8(t *testing.T) {
+	for _, f := range conformance.UTF8 {
+		t.Run(f.Name, func(t *testing.T) {
+			require.True(t, json.Valid(f.Data))
+			require.Equal(t, !strings.HasPrefix(f.Name, "i_utf8_"), utf8.Valid(f.Data))
+			require.True(t, utf8.Valid(bytes.ToValidUTF8(f.Data, []byte("?"))))
+		})
+	}
+}
+
+// newValidator returns a constructor of a stateless validator.
+func newValidator(valid func([]byte) bool) func() func([]byte) bool {
+	return func() func([]byte) bool { return valid }
+}
+
+// TestCheckNew checks that every check uses a new validator.
+func TestCheckNew(t *testing.T) {
+	// Accepts only the first input.
+	l := conformance.Library{Name: "stateful", New: func() func([]byte) bool {
+		calls := 0
+		return func([]byte) bool {
+			calls++
+			return calls == 1
+		}
+	}}
+	for i := 0; i < 3; i++ {
+		require.Equal(t, conformance.OutcomeAccepted, l.Check(nil, time.Second))
+	}
+}
+
+func TestWriteMarkdown(t *testing.T) {
+	block := make(chan struct{})
+	t.Cleanup(func() { close(block) })
+	files := []conformance.File{
+		{Name: "i_a.json", Expect: conformance.ExpectEither, Data: []byte(`i`)},
+		{Name: "n_b.json", Expect: conformance.ExpectReject, Data: []byte(`n`)},
+		{Name: "y_c.json", Expect: conformance.ExpectAccept, Data: []byte(`y`)},
+	}
+	m := conformance.Run("Test", files, []conformance.Library{
+		{Name: "all", New: newValidator(func([]byte) bool { return true })},
+		{Name: "strict", New: newValidator(func(src []byte) bool { return src[0] == 'y' })},
+		{Name: "panics", New: newValidator(func(src []byte) bool {
+			if src[0] == 'y' {
+				panic("y")
+			}
+			return false
+		})},
+		{Name: "blocks", New: newValidator(func(src []byte) bool {
+			if src[0] == 'n' {
+				<-block
+			}
+			return true
+		})},
+	}, 10*time.Millisecond)
+
+	var b strings.Builder
+	require.NoError(t, m.WriteMarkdown(&b))
+	require.Equal(t, "### Test summary\n\n"+
+		"|library|pass|fail|crash|timeout|accepted i_|\n"+
+		"|-|-:|-:|-:|-:|-:|\n"+
+		"|all|1/2|1|0|0|1/1|\n"+
+		"|strict|2/2|0|0|0|0/1|\n"+
+		"|panics|1/2|0|1|0|0/1|\n"+
+		"|blocks|1/2|0|0|1|1/1|\n\n"+
+		"### Test conformance\n\n"+
+		"|file|all|strict|panics|blocks|\n"+
+		"|-|-|-|-|-|\n"+
+		"|i_a.json|accepted|rejected|rejected|accepted|\n"+
+		"|n_b.json|fail|✓|✓|timeout|\n"+
+		"|y_c.json|✓|✓|crash|✓|\n\n", b.String())
+}
@@ -0,0 +1,14 @@
+package conformance
+
+import "github.com/romshark/jscan-benchmark/validators"
+
+// Libraries are all libraries and variants benchmarked by the validation
+// suite except those not supported on this machine.
+var Libraries = func() (l []Library) {
+	for _, v := range validators.All {
+		if v.Supported == nil || v.Supported() {
+			l = append(l, Library{Name: v.BytesName(), New: v.New})
+		}
+	}
+	return l
+}()
@@ -0,0 +1,36 @@
+package conformance
+
+// UTF8 is a catalog of inputs covering the handling of UTF-8 and of
+// escaped UTF-16 surrogates. Like in JSONTestSuite, strings containing
+// invalid UTF-8 or unpaired surrogate escapes are implementation
+// defined: JSON text exchanged between systems must be encoded in UTF-8
+// (RFC 8259, section 8.1) but parsers may replace invalid sequences.
+var UTF8 = []File{
+	{Name: "y_utf8_2_bytes", Expect: ExpectAccept, Data: []byte("[\"\xc3\xa9\"]")},
+	{Name: "y_utf8_3_bytes_cjk", Expect: ExpectAccept, Data: []byte("[\"\xe6\x97\xa5\xe6\x9c\xac\xe8\xaa\x9e\"]")},
+	{Name: "y_utf8_4_bytes_emoji", Expect: ExpectAccept, Data: []byte("[\"\xf0\x9f\x98\x80\"]")},
+	{Name: "y_utf8_emoji_zwj_sequence", Expect: ExpectAccept, Data: []byte("[\"\xf0\x9f\x91\xa8\xe2\x80\x8d\xf0\x9f\x91\xa9\xe2\x80\x8d\xf0\x9f\x91\xa7\"]")},
+	{Name: "y_utf8_max_code_point", Expect: ExpectAccept, Data: []byte("[\"\xf4\x8f\xbf\xbf\"]")},
+	{Name: "y_utf8_noncharacter", Expect: ExpectAccept, Data: []byte("[\"\xef\xbf\xbf\"]")},
+	{Name: "y_utf8_in_key", Expect: ExpectAccept, Data: []byte("{\"\xd0\xba\xd0\xbb\xd1\x8e\xd1\x87\":1}")},
+	{Name: "y_escape_surrogate_pair", Expect: ExpectAccept, Data: []byte(`["\ud83d\ude00"]`)},
+
+	{Name: "i_utf8_overlong_2_bytes", Expect: ExpectEither, Data: []byte("[\"\xc0\xaf\"]")},
+	{Name: "i_utf8_overlong_3_bytes", Expect: ExpectEither, Data: []byte("[\"\xe0\x80\xaf\"]")},
+	{Name: "i_utf8_overlong_4_bytes", Expect: ExpectEither, Data: []byte("[\"\xf0\x80\x80\xaf\"]")},
+	{Name: "i_utf8_overlong_nul", Expect: ExpectEither, Data: []byte("[\"\xc0\x80\"]")},
+	{Name: "i_utf8_encoded_high_surrogate", Expect: ExpectEither, Data: []byte("[\"\xed\xa0\x80\"]")},
+	{Name: "i_utf8_encoded_low_surrogate", Expect: ExpectEither, Data: []byte("[\"\xed\xb0\x80\"]")},
+	{Name: "i_utf8_beyond_max_code_point", Expect: ExpectEither, Data: []byte("[\"\xf4\x90\x80\x80\"]")},
+	{Name: "i_utf8_invalid_byte", Expect: ExpectEither, Data: []byte("[\"\xff\"]")},
+	{Name: "i_utf8_lone_continuation_byte", Expect: ExpectEither, Data: []byte("[\"\x80\"]")},
+	{Name: "i_utf8_truncated_2_bytes", Expect: ExpectEither, Data: []byte("[\"\xc3\"]")},
+	{Name: "i_utf8_truncated_3_bytes", Expect: ExpectEither, Data: []byte("[\"\xe6\x97\"]")},
+	{Name: "i_utf8_truncated_4_bytes", Expect: ExpectEither, Data: []byte("[\"\xf0\x9f\x98\"]")},
+	{Name: "i_utf8_truncated_in_key", Expect: ExpectEither, Data: []byte("{\"\xd0\":1}")},
+
+	{Name: "i_escape_lone_high_surrogate", Expect: ExpectEither, Data: []byte(`["\ud800"]`)},
+	{Name: "i_escape_lone_low_surrogate", Expect: ExpectEither, Data: []byte(`["\udc00"]`)},
+	{Name: "i_escape_reversed_surrogates", Expect: ExpectEither, Data: []byte(`["\udc00\ud800"]`)},
+	{Name: "i_escape_high_surrogate_before_char", Expect: ExpectEither, Data: []byte(`["\ud800a"]`)},
+}
@@ -0,0 +1,227 @@
+// Package depth sweeps the nesting depth of arrays and objects to find
+// the depth limits of the libraries and how their time and memory grow
+// with depth. Every check is meant to run in a separate process
+// so that a stack overflow doesn't abort the sweep, see cmd/depth.
+package depth
+
+import (
+	"fmt"
+	"io"
+	"runtime"
+	"strings"
+	"time"
+)
+
+// Depths are the nesting depths swept.
+var Depths = []int{16, 64, 256, 1024, 4096, 16384, 65536, 262144, 1000000}
+
+// Shape is a nested document.
+type Shape struct {
+	Name  string
+	Valid bool
+
+	// open and close are repeated depth times, the value
+	// is nested in the innermost level of valid documents.
+	open, value, close string
+}
+
+// Shapes are all shapes swept.
+var Shapes = []Shape{
+	{Name: "array", Valid: true, open: "[", close: "]"},
+	{Name: "object", Valid: true, open: `{"a":`, value: "null", close: "}"},
+	{Name: "array_unterminated", open: "["},
+	{Name: "object_unterminated", open: `{"a":`},
+}
+
+// LookupShape returns the shape with the given name.
+func LookupShape(name string) (Shape, bool) {
+	for _, s := range Shapes {
+		if s.Name == name {
+			return s, true
+		}
+	}
+	return Shape{}, false
+}
+
+// Make returns the document of shape s nested depth levels deep.
+func (s Shape) Make(depth int) []byte {
+	var b strings.Builder
+	b.Grow(depth*(len(s.open)+len(s.close)) + len(s.value))
+	for i := 0; i < depth; i++ {
+		b.WriteString(s.open)
+	}
+	b.WriteString(s.value)
+	for i := 0; i < depth; i++ {
+		b.WriteString(s.close)
+	}
+	return []byte(b.String())
+}
+
+// Outcome is the outcome of checking a document using a library.
+type Outcome string
+
+// Outcomes of a check.
+const (
+	OutcomeAccepted      Outcome = "accepted"
+	OutcomeRejected      Outcome = "rejected"
+	OutcomeStackOverflow Outcome = "stack overflow" // The goroutine stack limit was exceeded
+	OutcomeCrash         Outcome = "crash"          // The process died otherwise
+	OutcomeTimeout       Outcome = "timeout"        // The process didn't exit in time
+)
+
+// Result is the result of checking a document using a library.
+type Result struct {
+	Library string
+	Shape   string
+	Depth   int
+	Outcome Outcome
+
+	// Measurements are only available if the library returned.
+	NsPerOp    float64 // Average time per check
+	HeapBytes  uint64  // Heap allocated by the first check
+	StackBytes uint64  // Goroutine stack growth during the first check
+}
+
+// MinMeasureTime is the minimum duration checks are repeated for
+// to measure the average time per check.
+const MinMeasureTime = 100 * time.Millisecond
+
+// Measure checks src using valid and returns the outcome and measurements.
+// The first check runs in a new goroutine to measure its stack growth.
+func Measure(valid func(src []byte) bool, src []byte) Result {
+	r := Result{Outcome: OutcomeRejected}
+	var elapsed time.Duration
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		var before, after runtime.MemStats
+		runtime.GC()
+		runtime.ReadMemStats(&before)
+		start := time.Now()
+		if valid(src) {
+			r.Outcome = OutcomeAccepted
+		}
+		elapsed = time.Since(start)
+		// Stacks only shrink during garbage collection, the stack in use
+		// still includes the stack grown during the check.
+		runtime.ReadMemStats(&after)
+		r.HeapBytes = after.TotalAlloc - before.TotalAlloc
+		if after.StackInuse > before.StackInuse {
+			r.StackBytes = after.StackInuse - before.StackInuse
+		}
+	}()
+	<-done
+
+	n := 1
+	for ; elapsed < MinMeasureTime; n++ {
+		start := time.Now()
+		valid(src)
+		elapsed += time.Since(start)
+	}
+	r.NsPerOp = float64(elapsed.Nanoseconds()) / float64(n)
+	return r
+}
+
+// OutcomeOfExit returns the outcome of a process checking a document that
+// didn't exit successfully given the output written to stderr.
+func OutcomeOfExit(stderr []byte) Outcome {
+	if strings.Contains(string(stderr), "stack overflow") {
+		return OutcomeStackOverflow
+	}
+	return OutcomeCrash
+}
+
+// WriteMarkdown writes the outcome, time, heap and stack tables of results
+// for the given libraries to w. An outcome is a check mark if the library
+// accepted a valid or rejected an invalid document.
+func WriteMarkdown(w io.Writer, libraries []string, results []Result) error {
+	type key struct {
+		library, shape string
+		depth          int
+	}
+	m := make(map[key]Result, len(results))
+	for _, r := range results {
+		m[key{r.Library, r.Shape, r.Depth}] = r
+	}
+	var b strings.Builder
+	table := func(title string, cell func(Shape, Result) string) {
+		fmt.Fprintf(&b, "### %s\n\n|shape|depth|", title)
+		for _, l := range libraries {
+			fmt.Fprintf(&b, "%s|", l)
+		}
+		b.WriteString("\n|-|-:|")
+		for range libraries {
+			b.WriteString("-:|")
+		}
+		b.WriteByte('\n')
+		for _, s := range Shapes {
+			for _, d := range Depths {
+				row := fmt.Sprintf("|%s|%d|", s.Name, d)
+				found := false
+				for _, l := range libraries {
+					r, ok := m[key{l, s.Name, d}]
+					if ok {
+						found = true
+						row += cell(s, r)
+					}
+					row += "|"
+				}
+				if found {
+					b.WriteString(row + "\n")
+				}
+			}
+		}
+		b.WriteByte('\n')
+	}
+	measured := func(r Result) bool {
+		return r.Outcome == OutcomeAccepted || r.Outcome == OutcomeRejected
+	}
+	table("Nesting depth outcomes", func(s Shape, r Result) string {
+		if (r.Outcome == OutcomeAccepted) == s.Valid && measured(r) {
+			return "✓"
+		}
+		return string(r.Outcome)
+	})
+	table("Nesting depth time per check", func(s Shape, r Result) string {
+		if !measured(r) {
+			return "-"
+		}
+		return formatDuration(time.Duration(r.NsPerOp))
+	})
+	table("Nesting depth heap allocated per check", func(s Shape, r Result) string {
+		if !measured(r) {
+			return "-"
+		}
+		return formatBytes(r.HeapBytes)
+	})
+	table("Nesting depth stack growth per check", func(s Shape, r Result) string {
+		if !measured(r) {
+			return "-"
+		}
+		return formatBytes(r.StackBytes)
+	})
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func formatDuration(d time.Duration) string {
+	switch {
+	case d < time.Microsecond:
+		return fmt.Sprintf("%dns", d.Nanoseconds())
+	case d < time.Millisecond:
+		return fmt.Sprintf("%.1fµs", float64(d)/float64(time.Microsecond))
+	case d < time.Second:
+		return fmt.Sprintf("%.1fms", float64(d)/float64(time.Millisecond))
+	}
+	return fmt.Sprintf("%.2fs", d.Seconds())
+}
+
+func formatBytes(n uint64) string {
+	switch {
+	case n < 1<<10:
+		return fmt.Sprintf("%d B", n)
+	case n < 1<<20:
+		return fmt.Sprintf("%.1f KiB", float64(n)/(1<<10))
+	}
+	return fmt.Sprintf("%.1f MiB", float64(n)/(1<<20))
+}
@@ -0,0 +1,84 @@
+package depth_test
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/romshark/jscan-benchmark/depth"
+
+	"github.com/romshark/jscan/v2"
+	"github.com/stretchr/testify/require"
+)
+
+func TestShapes(t *testing.T) {
+	for _, s := range depth.Shapes {
+		t.Run(s.Name, func(t *testing.T) {
+			src := s.Make(64)
+			require.Equal(t, s.Valid, json.Valid(src))
+			if !s.Valid {
+				return
+			}
+			maxLevel := 0
+			err := jscan.Scan(src, func(i *jscan.Iterator[[]byte]) (err bool) {
+				if t := i.ValueType(); t == jscan.ValueTypeArray || t == jscan.ValueTypeObject {
+					maxLevel = max(maxLevel, i.Level())
+				}
+				return false
+			})
+			require.False(t, err.IsErr())
+			require.Equal(t, 63, maxLevel, "containers nested 64 levels deep")
+		})
+	}
+}
+
+func TestMeasure(t *testing.T) {
+	src := depth.Shapes[0].Make(16)
+	r := depth.Measure(func([]byte) bool { return true }, src)
+	require.Equal(t, depth.OutcomeAccepted, r.Outcome)
+	require.Greater(t, r.NsPerOp, 0.0)
+
+	var sink []byte
+	r = depth.Measure(func(src []byte) bool {
+		sink = make([]byte, 1<<20)
+		return false
+	}, src)
+	require.Equal(t, depth.OutcomeRejected, r.Outcome)
+	require.GreaterOrEqual(t, r.HeapBytes, uint64(1<<20))
+	_ = sink
+}
+
+func TestOutcomeOfExit(t *testing.T) {
+	require.Equal(t, depth.OutcomeStackOverflow, depth.OutcomeOfExit([]byte(
+		"runtime: goroutine stack exceeds 1000000000-byte limit\n"+
+			"fatal error: stack overflow\n")))
+	require.Equal(t, depth.OutcomeCrash, depth.OutcomeOfExit([]byte(
+		"panic: runtime error: index out of range [3] with length 3\n")))
+}
+
+func TestWriteMarkdown(t *testing.T) {
+	var b strings.Builder
+	require.NoError(t, depth.WriteMarkdown(&b, []string{"a", "b"}, []depth.Result{
+		{Library: "a", Shape: "array", Depth: 16, Outcome: depth.OutcomeAccepted,
+			NsPerOp: 1500, HeapBytes: 2048, StackBytes: 0},
+		{Library: "b", Shape: "array", Depth: 16, Outcome: depth.OutcomeStackOverflow},
+		{Library: "a", Shape: "array_unterminated", Depth: 16, Outcome: depth.OutcomeAccepted,
+			NsPerOp: 10, HeapBytes: 8, StackBytes: 32 << 10},
+	}))
+	require.Equal(t, "### Nesting depth outcomes\n\n"+
+		"|shape|depth|a|b|\n|-|-:|-:|-:|\n"+
+		"|array|16|✓|stack overflow|\n"+
+		"|array_unterminated|16|accepted||\n\n"+
+		"### Nesting depth time per check\n\n"+
+		"|shape|depth|a|b|\n|-|-:|-:|-:|\n"+
+		"|array|16|1.5µs|-|\n"+
+		"|array_unterminated|16|10ns||\n\n"+
+		"### Nesting depth heap allocated per check\n\n"+
+		"|shape|depth|a|b|\n|-|-:|-:|-:|\n"+
+		"|array|16|2.0 KiB|-|\n"+
+		"|array_unterminated|16|8 B||\n\n"+
+		"### Nesting depth stack growth per check\n\n"+
+		"|shape|depth|a|b|\n|-|-:|-:|-:|\n"+
+		"|array|16|0 B|-|\n"+
+		"|array_unterminated|16|32.0 KiB||\n\n", b.String())
+}
@@ -0,0 +1,186 @@
+// Package errormatrix compares the syntax errors reported by the libraries
+// for a catalog of malformed inputs. The positions and kinds of errors
+// reported in each library's own way are normalized to byte offsets
+// and categories so that they can be checked against the catalog.
+package errormatrix
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// Category is the kind of a syntax error.
+type Category string
+
+// Error categories modeled after the jscan error codes.
+const (
+	CategoryUnexpectedToken Category = "unexpected token"
+	CategoryMalformedNumber Category = "malformed number"
+	CategoryUnexpectedEOF   Category = "unexpected EOF"
+	CategoryInvalidEscape   Category = "invalid escape"
+	CategoryControlChar     Category = "control character"
+)
+
+// Case is a malformed input.
+type Case struct {
+	Name  string
+	Input string
+
+	// Offset is the index of the first byte at which the input stops being
+	// the beginning of any valid JSON document, len(Input) if the input
+	// ends prematurely.
+	Offset int
+
+	Category Category
+}
+
+// Catalog is the catalog of malformed inputs.
+var Catalog = []Case{
+	{"unexpected_char", `[1,2,#]`, 5, CategoryUnexpectedToken},
+	{"trailing_comma_array", `[1,2,]`, 5, CategoryUnexpectedToken},
+	{"trailing_comma_object", `{"a":1,}`, 7, CategoryUnexpectedToken},
+	{"missing_colon", `{"a" 1}`, 5, CategoryUnexpectedToken},
+	{"missing_comma", `[1 2]`, 3, CategoryUnexpectedToken},
+	{"single_quotes", `{'a':1}`, 1, CategoryUnexpectedToken},
+	{"unquoted_key", `{a:1}`, 1, CategoryUnexpectedToken},
+	{"truncated_literal", `[tru]`, 4, CategoryUnexpectedToken},
+	{"plus_sign", `[+1]`, 1, CategoryUnexpectedToken},
+	{"second_document", `{} {}`, 3, CategoryUnexpectedToken},
+	{"nested_line_break", "{\n  \"a\": [\n    1,\n    ]\n}", 22, CategoryUnexpectedToken},
+	{"leading_zero", `[01]`, 2, CategoryMalformedNumber},
+	{"fraction_without_digits", `[1.]`, 3, CategoryMalformedNumber},
+	{"exponent_without_digits", `[1e]`, 3, CategoryMalformedNumber},
+	{"minus_without_digits", `[-]`, 2, CategoryMalformedNumber},
+	{"invalid_escape", `["a\x"]`, 4, CategoryInvalidEscape},
+	{"invalid_unicode_escape", `["\u12G4"]`, 6, CategoryInvalidEscape},
+	{"tab_in_string", "[\"a\tb\"]", 3, CategoryControlChar},
+	{"line_break_in_string", "[\"a\nb\"]", 3, CategoryControlChar},
+	{"unterminated_string", `["abc`, 5, CategoryUnexpectedEOF},
+	{"unterminated_array", `[1,2`, 4, CategoryUnexpectedEOF},
+	{"unterminated_object", `{"a":`, 5, CategoryUnexpectedEOF},
+	{"unterminated_nesting", `[[[`, 3, CategoryUnexpectedEOF},
+	{"empty", ``, 0, CategoryUnexpectedEOF},
+	{"whitespace_only", `  `, 2, CategoryUnexpectedEOF},
+}
+
+// Report is the normalized error reported by a library for an input.
+type Report struct {
+	Valid bool
+
+	// Offset is the reported byte offset of the error,
+	// -1 if the library doesn't report it.
+	Offset int
+
+	// Category is the reported kind of error,
+	// empty if the library doesn't report it.
+	Category Category
+
+	// Message is the error message of the library.
+	Message string
+}
+
+// Library checks inputs using a JSON library.
+type Library struct {
+	Name  string
+	Check func(src []byte) Report
+}
+
+// Matrix contains the report of every library for every case.
+type Matrix struct {
+	Cases     []Case
+	Libraries []string
+	Reports   [][]Report // Indexed by case then by library
+}
+
+// Run checks all cases using all libraries.
+func Run(cases []Case, libraries []Library) *Matrix {
+	m := &Matrix{Cases: cases, Reports: make([][]Report, len(cases))}
+	for _, l := range libraries {
+		m.Libraries = append(m.Libraries, l.Name)
+	}
+	for i, c := range cases {
+		m.Reports[i] = make([]Report, len(libraries))
+		for j, l := range libraries {
+			m.Reports[i][j] = l.Check([]byte(c.Input))
+		}
+	}
+	return m
+}
+
+// WriteMarkdown writes the offset and category tables of the matrix to w.
+// A cell is a check mark if the library reported the right offset
+// or category, the wrong offset relative to the expected one or the wrong
+// category, "?" if the library doesn't report it and "accepted"
+// if the library accepted the malformed input.
+func (m *Matrix) WriteMarkdown(w io.Writer) error {
+	var b strings.Builder
+	m.writeTable(&b, "Error offsets", func(c Case) string {
+		return strconv.Itoa(c.Offset)
+	}, func(c Case, r Report) (string, bool) {
+		switch {
+		case r.Offset < 0:
+			return "?", false
+		case r.Offset == c.Offset:
+			return "✓", true
+		}
+		return fmt.Sprintf("%d (%+d)", r.Offset, r.Offset-c.Offset), false
+	})
+	m.writeTable(&b, "Error categories", func(c Case) string {
+		return string(c.Category)
+	}, func(c Case, r Report) (string, bool) {
+		switch r.Category {
+		case "":
+			return "?", false
+		case c.Category:
+			return "✓", true
+		}
+		return string(r.Category), false
+	})
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func (m *Matrix) writeTable(
+	b *strings.Builder, title string,
+	expect func(Case) string, cell func(Case, Report) (string, bool),
+) {
+	fmt.Fprintf(b, "### %s\n\n|case|input|expected|", title)
+	for _, l := range m.Libraries {
+		fmt.Fprintf(b, "%s|", l)
+	}
+	b.WriteString("\n|-|-|-|")
+	for range m.Libraries {
+		b.WriteString("-|")
+	}
+	b.WriteByte('\n')
+	correct := make([]int, len(m.Libraries))
+	for i, c := range m.Cases {
+		input := "(empty)"
+		if c.Input != "" {
+			input = "`" + inputEscaper.Replace(c.Input) + "`"
+		}
+		fmt.Fprintf(b, "|%s|%s|%s|", c.Name, input, expect(c))
+		for j, r := range m.Reports[i] {
+			if r.Valid {
+				b.WriteString("accepted|")
+				continue
+			}
+			s, ok := cell(c, r)
+			if ok {
+				correct[j]++
+			}
+			fmt.Fprintf(b, "%s|", s)
+		}
+		b.WriteByte('\n')
+	}
+	b.WriteString("|**correct**|||")
+	for _, n := range correct {
+		fmt.Fprintf(b, "**%d/%d**|", n, len(m.Cases))
+	}
+	b.WriteString("\n\n")
+}
+
+// inputEscaper makes inputs printable in markdown table cells.
+var inputEscaper = strings.NewReplacer("\n", `\n`, "\t", `\t`, "|", `\|`)
@@ -0,0 +1,184 @@
+package errormatrix_test
+
+import (
+	"encoding/json"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/romshark/jscan-benchmark/errormatrix"
+
+	"github.com/stretchr/testify/require"
+)
+
+// TestCatalog checks the expected offsets of the catalog against
+// encoding/json: the input is the beginning of a valid document
+// up to the offset but not including the byte at the offset.
+func TestCatalog(t *testing.T) {
+	isPrefix := func(src string) bool {
+		d := json.NewDecoder(strings.NewReader(src))
+		var v any
+		switch err := d.Decode(&v); err {
+		case io.EOF, io.ErrUnexpectedEOF:
+			return true
+		case nil:
+			return strings.TrimSpace(src[d.InputOffset():]) == ""
+		}
+		return false
+	}
+	names := map[string]bool{}
+	for _, c := range errormatrix.Catalog {
+		t.Run(c.Name, func(t *testing.T) {
+			require.False(t, names[c.Name], "duplicate name")
+			names[c.Name] = true
+			require.False(t, json.Valid([]byte(c.Input)))
+			require.LessOrEqual(t, c.Offset, len(c.Input))
+			require.True(t, isPrefix(c.Input[:c.Offset]))
+			if c.Offset < len(c.Input) {
+				require.False(t, isPrefix(c.Input[:c.Offset+1]))
+			} else {
+				require.Equal(t, errormatrix.CategoryUnexpectedEOF, c.Category)
+			}
+		})
+	}
+}
+
+// TestLibraries checks the normalized reports of each library for
+// catalog cases covering the way it reports offsets and categories.
+func TestLibraries(t *testing.T) {
+	type expect struct {
+		name     string
+		offset   int
+		category errormatrix.Category
+	}
+	expects := map[string][]expect{
+		"jscan": {
+			{"missing_comma", 3, errormatrix.CategoryUnexpectedToken},
+			{"tab_in_string", 3, errormatrix.CategoryControlChar},
+			{"unterminated_nesting", 3, errormatrix.CategoryUnexpectedEOF},
+		},
+		// SyntaxError.Offset includes the offending byte
+		// except at the end of the input.
+		"encoding_json": {
+			{"trailing_comma_array", 5, errormatrix.CategoryUnexpectedToken},
+			{"invalid_escape", 4, errormatrix.CategoryInvalidEscape},
+			{"unterminated_array", 4, errormatrix.CategoryUnexpectedEOF},
+			{"empty", 0, errormatrix.CategoryUnexpectedEOF},
+		},
+		// The window starts at the beginning of the input, ends at its end
+		// or is located in the input.
+		"jsoniter": {
+			{"unexpected_char", 5, errormatrix.CategoryUnexpectedToken},
+			{"nested_line_break", 22, errormatrix.CategoryUnexpectedToken},
+			{"invalid_unicode_escape", 6, errormatrix.CategoryInvalidEscape},
+			{"unterminated_string", 5, errormatrix.CategoryUnexpectedEOF},
+		},
+		"gofaster_jx": {
+			{"unexpected_char", 5, errormatrix.CategoryUnexpectedToken},
+			{"unterminated_array", 4, errormatrix.CategoryUnexpectedEOF},
+		},
+		"tidwall_gjson_bytes": {
+			{"unexpected_char", -1, ""},
+		},
+		"valyala_fastjson": {
+			{"trailing_comma_object", 7, errormatrix.CategoryUnexpectedToken},
+			{"exponent_without_digits", 3, errormatrix.CategoryMalformedNumber},
+			{"unterminated_string", 5, errormatrix.CategoryUnexpectedEOF},
+		},
+		"goccy_go_json": {
+			{"unexpected_char", 5, errormatrix.CategoryUnexpectedToken},
+			{"invalid_unicode_escape", 6, errormatrix.CategoryInvalidEscape},
+			{"empty", 0, errormatrix.CategoryUnexpectedEOF},
+		},
+		"bytedance_sonic": {
+			{"missing_colon", 5, errormatrix.CategoryUnexpectedToken},
+			{"unterminated_object", 5, errormatrix.CategoryUnexpectedEOF},
+		},
+		"ohler55_ojg_oj": {
+			{"nested_line_break", 22, errormatrix.CategoryUnexpectedToken},
+			{"invalid_escape", 4, errormatrix.CategoryInvalidEscape},
+			{"leading_zero", 2, errormatrix.CategoryMalformedNumber},
+		},
+		"minio_simdjson": {
+			{"unexpected_char", -1, ""},
+		},
+		"jeffail_gabs": {
+			{"trailing_comma_array", 5, errormatrix.CategoryUnexpectedToken},
+			{"unterminated_object", 5, errormatrix.CategoryUnexpectedEOF},
+		},
+	}
+	cases := map[string]errormatrix.Case{}
+	for _, c := range errormatrix.Catalog {
+		cases[c.Name] = c
+	}
+	for _, l := range errormatrix.Libraries {
+		t.Run(l.Name, func(t *testing.T) {
+			require.Contains(t, expects, l.Name)
+			for _, e := range expects[l.Name] {
+				require.Contains(t, cases, e.name)
+				r := l.Check([]byte(cases[e.name].Input))
+				require.False(t, r.Valid, e.name)
+				require.Equal(t, e.offset, r.Offset, e.name)
+				require.Equal(t, e.category, r.Category, e.name)
+			}
+		})
+	}
+}
+
+// TestJsoniterWindow checks that the offset isn't derived from
+// a window occurring in the input more than once.
+func TestJsoniterWindow(t *testing.T) {
+	var jsoniter errormatrix.Library
+	for _, l := range errormatrix.Libraries {
+		if l.Name == "jsoniter" {
+			jsoniter = l
+		}
+	}
+	const s = `0,0,0,0,0,0,0,0,0,0,]0,0,0,0,0,0,0,0,0,1`
+	r := jsoniter.Check([]byte(`[` + s + `]`))
+	require.Equal(t, 21, r.Offset)
+	require.Equal(t, errormatrix.CategoryUnexpectedToken, r.Category)
+
+	// The window also occurs in the string preceding the error.
+	r = jsoniter.Check([]byte(`["` + s + `",` + s + `]`))
+	require.Equal(t, -1, r.Offset)
+	require.Equal(t, errormatrix.CategoryUnexpectedToken, r.Category)
+}
+
+func TestWriteMarkdown(t *testing.T) {
+	m := errormatrix.Run([]errormatrix.Case{
+		{"a", `[1,]`, 3, errormatrix.CategoryUnexpectedToken},
+		{"b", ``, 0, errormatrix.CategoryUnexpectedEOF},
+	}, []errormatrix.Library{
+		{Name: "exact", Check: func(src []byte) errormatrix.Report {
+			if len(src) == 0 {
+				return errormatrix.Report{
+					Offset: 0, Category: errormatrix.CategoryUnexpectedEOF,
+				}
+			}
+			return errormatrix.Report{
+				Offset: 3, Category: errormatrix.CategoryUnexpectedToken,
+			}
+		}},
+		{Name: "off", Check: func(src []byte) errormatrix.Report {
+			if len(src) == 0 {
+				return errormatrix.Report{Valid: true, Offset: -1}
+			}
+			return errormatrix.Report{Offset: 1}
+		}},
+	})
+	var b strings.Builder
+	require.NoError(t, m.WriteMarkdown(&b))
+	require.Equal(t, "### Error offsets\n\n"+
+		"|case|input|expected|exact|off|\n"+
+		"|-|-|-|-|-|\n"+
+		"|a|`[1,]`|3|✓|1 (-2)|\n"+
+		"|b|(empty)|0|✓|accepted|\n"+
+		"|**correct**|||**2/2**|**0/2**|\n\n"+
+		"### Error categories\n\n"+
+		"|case|input|expected|exact|off|\n"+
+		"|-|-|-|-|-|\n"+
+		"|a|`[1,]`|unexpected token|✓|?|\n"+
+		"|b|(empty)|unexpected EOF|✓|accepted|\n"+
+		"|**correct**|||**2/2**|**0/2**|\n\n", b.String())
+}
@@ -0,0 +1,352 @@
+package errormatrix
+
+import (
+	encodingjson "encoding/json"
+	"errors"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/romshark/jscan/v2"
+
+	jeffailgabs "github.com/Jeffail/gabs"
+	bytedancesonic "github.com/bytedance/sonic"
+	gofasterjx "github.com/go-faster/jx"
+	goccygojson "github.com/goccy/go-json"
+	jsoniter "github.com/json-iterator/go"
+	miniosimdjson "github.com/minio/simdjson-go"
+	ohler55ojgoj "github.com/ohler55/ojg/oj"
+	tidwallgjson "github.com/tidwall/gjson"
+	valyalafastjson "github.com/valyala/fastjson"
+)
+
+// Libraries are the libraries compared. Each normalizes its errors
+// according to its documented semantics, offsets and categories not
+// exposed by a library are extracted from its error messages.
+var Libraries = []Library{
+	{Name: "jscan", Check: checkJscan},
+	{Name: "encoding_json", Check: checkEncodingJSON},
+	{Name: "jsoniter", Check: checkJsoniter},
+	{Name: "gofaster_jx", Check: checkGofasterJx},
+	{Name: "tidwall_gjson_bytes", Check: checkTidwallGjson},
+	{Name: "valyala_fastjson", Check: checkValyalaFastjson},
+	{Name: "goccy_go_json", Check: checkGoccyGoJSON},
+	{Name: "bytedance_sonic", Check: checkBytedanceSonic},
+	{Name: "ohler55_ojg_oj", Check: checkOhler55OjgOj},
+	{Name: "minio_simdjson", Check: checkMinioSimdjson},
+	{Name: "jeffail_gabs", Check: checkJeffailGabs},
+}
+
+// unknown returns a report of an error without offset and category.
+func unknown(err error) Report {
+	if err == nil {
+		return Report{Valid: true, Offset: -1}
+	}
+	return Report{Offset: -1, Message: err.Error()}
+}
+
+func checkJscan(src []byte) Report {
+	err := jscan.Validate(src)
+	if !err.IsErr() {
+		return unknown(nil)
+	}
+	r := Report{Offset: err.Index, Message: err.Error()}
+	switch err.Code {
+	case jscan.ErrorCodeInvalidEscape:
+		r.Category = CategoryInvalidEscape
+	case jscan.ErrorCodeIllegalControlChar:
+		r.Category = CategoryControlChar
+	case jscan.ErrorCodeUnexpectedEOF:
+		r.Category = CategoryUnexpectedEOF
+	case jscan.ErrorCodeUnexpectedToken:
+		r.Category = CategoryUnexpectedToken
+	case jscan.ErrorCodeMalformedNumber:
+		r.Category = CategoryMalformedNumber
+	}
+	return r
+}
+
+// checkEncodingJSON uses a decoder since Valid doesn't return errors.
+func checkEncodingJSON(src []byte) Report {
+	var v any
+	return encodingJSONReport(encodingjson.Unmarshal(src, &v))
+}
+
+// encodingJSONReport normalizes the errors of encoding/json.
+// SyntaxError.Offset is the number of bytes read before the error
+// including the offending byte.
+func encodingJSONReport(err error) Report {
+	r := unknown(err)
+	var s *encodingjson.SyntaxError
+	if !errors.As(err, &s) {
+		return r
+	}
+	r.Offset = int(s.Offset)
+	if !strings.Contains(s.Error(), "unexpected end of JSON input") {
+		r.Offset--
+	}
+	r.Category = categorize(s.Error(), encodingJSONRules)
+	return r
+}
+
+// rule categorizes error messages containing a keyword.
+type rule struct {
+	keyword  string
+	category Category
+}
+
+// categorize returns the category of the first rule
+// whose keyword msg contains, or an empty category.
+func categorize(msg string, rules []rule) Category {
+	for _, r := range rules {
+		if strings.Contains(msg, r.keyword) {
+			return r.category
+		}
+	}
+	return ""
+}
+
+// encodingJSONRules categorize the messages of encoding/json
+// and libraries mimicking it.
+var encodingJSONRules = []rule{
+	{"unexpected end of JSON input", CategoryUnexpectedEOF},
+	{"in string escape code", CategoryInvalidEscape},
+	{"in \\u hexadecimal character escape", CategoryInvalidEscape},
+	{"in string literal", CategoryControlChar},
+	{"in numeric literal", CategoryMalformedNumber},
+	{"after top-level value", CategoryUnexpectedToken},
+	{"invalid character", CategoryUnexpectedToken},
+}
+
+// jsoniterPeek matches the position of the iterator relative to the
+// start of the window of up to 10 bytes before and after it
+// included in jsoniter error messages, and the window.
+var jsoniterPeek = regexp.MustCompile(`(?s)error found in #(\d+) byte of \.\.\.\|(.*?)\|\.\.\., bigger context`)
+
+var jsoniterRules = []rule{
+	{"do not know how to skip: 0,", CategoryUnexpectedEOF},
+	{"end of input", CategoryUnexpectedEOF},
+	{"\x00", CategoryUnexpectedEOF},
+	{"escape", CategoryInvalidEscape},
+	{"readU4", CategoryInvalidEscape},
+	{"control character", CategoryControlChar},
+	{"leading zero", CategoryMalformedNumber},
+	{"digit", CategoryMalformedNumber},
+	{"invalid number", CategoryMalformedNumber},
+	{"do not know how to skip", CategoryUnexpectedToken},
+	{"expect", CategoryUnexpectedToken},
+	{"unexpected", CategoryUnexpectedToken},
+}
+
+// checkJsoniter skips the input using an iterator. When the error
+// is reported the iterator is positioned after the offending byte unless
+// it reached the end of the input where it reads zero bytes
+// without advancing.
+func checkJsoniter(src []byte) Report {
+	it := jsoniter.ConfigDefault.BorrowIterator(src)
+	defer jsoniter.ConfigDefault.ReturnIterator(it)
+	it.Skip()
+	if it.Error == nil && it.WhatIsNext() != jsoniter.InvalidValue {
+		it.ReportError("Skip", "unexpected data after top-level value")
+	}
+	if it.Error == io.EOF {
+		return unknown(nil)
+	}
+	r := unknown(it.Error)
+	r.Category = categorize(r.Message, jsoniterRules)
+	if m := jsoniterPeek.FindStringSubmatch(r.Message); m != nil {
+		n, _ := strconv.Atoi(m[1])
+		if r.Offset = jsoniterPosition(string(src), m[2], n); r.Offset > 0 &&
+			r.Category != CategoryUnexpectedEOF {
+			r.Offset--
+		}
+	}
+	return r
+}
+
+// jsoniterPeekLen is the maximum number of bytes before and after
+// the position of the iterator included in the window.
+const jsoniterPeekLen = 10
+
+// jsoniterPosition returns the position of the iterator in src given the
+// window and the position n relative to its start, or -1 if the window
+// can't be located unambiguously. A window starting less than
+// jsoniterPeekLen bytes before the position starts at the beginning of
+// src and one ending less than jsoniterPeekLen bytes after it ends at
+// the end of src, any other window must occur in src only once.
+func jsoniterPosition(src, window string, n int) int {
+	start := -1
+	switch {
+	case n < jsoniterPeekLen:
+		if strings.HasPrefix(src, window) {
+			start = 0
+		}
+	case len(window) < n+jsoniterPeekLen:
+		if strings.HasSuffix(src, window) {
+			start = len(src) - len(window)
+		}
+	default:
+		if start = strings.Index(src, window); start != -1 &&
+			strings.Contains(src[start+1:], window) {
+			start = -1
+		}
+	}
+	if start == -1 {
+		return -1
+	}
+	return start + n
+}
+
+// jxOffset matches the offset of the unexpected byte in jx error messages.
+var jxOffset = regexp.MustCompile(`unexpected byte \d+ '.*' at (\d+)`)
+
+func checkGofasterJx(src []byte) Report {
+	d := gofasterjx.DecodeBytes(src)
+	err := d.Validate()
+	r := unknown(err)
+	if err == nil {
+		return r
+	}
+	switch m := jxOffset.FindStringSubmatch(r.Message); {
+	case m != nil:
+		r.Offset, _ = strconv.Atoi(m[1])
+		r.Category = CategoryUnexpectedToken
+	case errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, io.EOF):
+		r.Offset = len(src)
+		r.Category = CategoryUnexpectedEOF
+	default:
+		r.Category = categorize(r.Message, jxRules)
+	}
+	return r
+}
+
+var jxRules = []rule{
+	{"escape", CategoryInvalidEscape},
+	{"control", CategoryControlChar},
+	{"number", CategoryMalformedNumber},
+	{"digit", CategoryMalformedNumber},
+	{"unexpected", CategoryUnexpectedToken},
+}
+
+// checkTidwallGjson only reports validity.
+func checkTidwallGjson(src []byte) Report {
+	if tidwallgjson.ValidBytes(src) {
+		return unknown(nil)
+	}
+	return unknown(errors.New("invalid"))
+}
+
+// checkValyalaFastjson derives the offset from the unparsed tail
+// included in the error message.
+func checkValyalaFastjson(src []byte) Report {
+	err := valyalafastjson.ValidateBytes(src)
+	r := unknown(err)
+	if err == nil {
+		return r
+	}
+	const tailPrefix = "; unparsed tail: "
+	if i := strings.LastIndex(r.Message, tailPrefix); i != -1 {
+		tail, errUnquote := strconv.Unquote(r.Message[i+len(tailPrefix):])
+		if errUnquote == nil && strings.HasSuffix(string(src), tail) {
+			r.Offset = len(src) - len(tail)
+		}
+	}
+	r.Category = categorize(r.Message, fastjsonRules)
+	return r
+}
+
+var fastjsonRules = []rule{
+	{"cannot parse empty string", CategoryUnexpectedEOF},
+	{"missing closing", CategoryUnexpectedEOF},
+	{"missing ']'", CategoryUnexpectedEOF},
+	{"missing '}'", CategoryUnexpectedEOF},
+	{"unexpected end", CategoryUnexpectedEOF},
+	{"escape sequence", CategoryInvalidEscape},
+	{"control char", CategoryControlChar},
+	{"cannot parse number", CategoryMalformedNumber},
+	{"unexpected number", CategoryMalformedNumber},
+	{"missing", CategoryUnexpectedToken},
+	{"cannot find", CategoryUnexpectedToken},
+	{"unexpected", CategoryUnexpectedToken},
+}
+
+// checkGoccyGoJSON uses a decoder since Valid doesn't return errors.
+// Unlike in encoding/json, SyntaxError.Offset is the offset
+// of the offending byte.
+func checkGoccyGoJSON(src []byte) Report {
+	var v any
+	err := goccygojson.Unmarshal(src, &v)
+	r := unknown(err)
+	var s *goccygojson.SyntaxError
+	if errors.As(err, &s) {
+		r.Offset = int(s.Offset)
+		r.Category = categorize(s.Error(), goccyRules)
+	}
+	return r
+}
+
+// goccyRules extend encodingJSONRules. At the end of the input
+// goccy/go-json reads a terminating null byte.
+var goccyRules = append(append([]rule{
+	{"'\x00'", CategoryUnexpectedEOF},
+}, encodingJSONRules...), []rule{
+	{"expected", CategoryUnexpectedToken},
+	{"strconv.ParseFloat", CategoryMalformedNumber},
+}...)
+
+// checkBytedanceSonic uses a decoder since Valid doesn't return errors.
+func checkBytedanceSonic(src []byte) Report {
+	var v any
+	return sonicReport(src, bytedancesonic.ConfigDefault.Unmarshal(src, &v))
+}
+
+// checkOhler55OjgOj converts the 1-based line and column to an offset.
+func checkOhler55OjgOj(src []byte) Report {
+	err := new(ohler55ojgoj.Validator).Validate(src)
+	r := unknown(err)
+	var p *ohler55ojgoj.ParseError
+	if errors.As(err, &p) {
+		r.Offset = lineColumnOffset(src, p.Line, p.Column)
+		r.Category = categorize(p.Message, ojgRules)
+	}
+	return r
+}
+
+var ojgRules = []rule{
+	{"incomplete JSON", CategoryUnexpectedEOF},
+	{"unicode character", CategoryInvalidEscape},
+	{"escape", CategoryInvalidEscape},
+	{"invalid JSON character", CategoryControlChar},
+	{"number", CategoryMalformedNumber},
+	{"unexpected character", CategoryUnexpectedToken},
+	{"expected", CategoryUnexpectedToken},
+}
+
+// lineColumnOffset returns the offset of the 1-based line and column in src.
+func lineColumnOffset(src []byte, line, column int) int {
+	offset := 0
+	for l := 1; l < line; l++ {
+		i := strings.IndexByte(string(src[offset:]), '\n')
+		if i == -1 {
+			return -1
+		}
+		offset += i + 1
+	}
+	return offset + column - 1
+}
+
+// checkMinioSimdjson only reports validity on supported CPUs.
+func checkMinioSimdjson(src []byte) Report {
+	if !miniosimdjson.SupportedCPU() {
+		return unknown(errors.New("unsupported CPU"))
+	}
+	_, err := miniosimdjson.Parse(src, nil)
+	return unknown(err)
+}
+
+// checkJeffailGabs reports the errors of the underlying encoding/json decoder.
+func checkJeffailGabs(src []byte) Report {
+	_, err := jeffailgabs.ParseJSON(src)
+	return encodingJSONReport(err)
+}
@@ -0,0 +1,29 @@
+//go:build amd64 && go1.16 && !go1.22
+
+package errormatrix
+
+import (
+	"errors"
+
+	bytedancesonicdecoder "github.com/bytedance/sonic/decoder"
+)
+
+// sonicReport normalizes the errors of the sonic decoder,
+// which reports the offset of the offending byte.
+func sonicReport(src []byte, err error) Report {
+	r := unknown(err)
+	var s bytedancesonicdecoder.SyntaxError
+	if errors.As(err, &s) {
+		r.Offset = s.Pos
+		r.Category = categorize(s.Description(), sonicRules)
+	}
+	return r
+}
+
+var sonicRules = []rule{
+	{": eof", CategoryUnexpectedEOF},
+	{"no sources available", CategoryUnexpectedEOF},
+	{"invalid escape", CategoryInvalidEscape},
+	{"invalid number", CategoryMalformedNumber},
+	{"invalid char", CategoryUnexpectedToken},
+}
@@ -0,0 +1,21 @@
+//go:build !amd64 || !go1.16 || go1.22
+
+package errormatrix
+
+import (
+	"errors"
+	"io"
+)
+
+// sonicReport normalizes the errors of sonic on targets its decoder
+// doesn't support, where it falls back to the encoding/json Decoder.
+// The Decoder reports inputs ending prematurely as io.EOF or
+// io.ErrUnexpectedEOF instead of a SyntaxError.
+func sonicReport(src []byte, err error) Report {
+	if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return Report{
+			Offset: len(src), Category: CategoryUnexpectedEOF, Message: err.Error(),
+		}
+	}
+	return encodingJSONReport(err)
+}
@@ -0,0 +1,62 @@
+// Package jscanversions is the registry of additional jscan versions
+// benchmarked side by side with the version pinned in go.mod.
+//
+// Versions are vendored under distinct import paths by cmd/jscanversions,
+// each registering itself in a generated file. The registry is empty
+// unless versions were generated, in which case every suite benchmarks
+// each of them as a separate library along with Pinned.
+package jscanversions
+
+import (
+	"sort"
+
+	"github.com/romshark/jscan/v2"
+)
+
+// EnvLocal is the environment variable holding the path of a local
+// jscan checkout vendored by cmd/jscanversions as "jscan_local".
+const EnvLocal = "JSCANBENCH_JSCAN_LOCAL"
+
+// Iterator is the subset of the jscan iterator used by the suites.
+// Value types are converted to the type of the pinned version.
+// The pinned *jscan.Iterator[[]byte] implements Iterator.
+type Iterator interface {
+	Level() int
+	ArrayIndex() int
+	KeyIndex() int
+	KeyIndexEnd() int
+	ValueType() jscan.ValueType
+	Value() []byte
+}
+
+// ScanFunc calls fn for every value of src until fn returns true.
+type ScanFunc func(src []byte, fn func(Iterator) (err bool)) error
+
+// Version is a vendored jscan version.
+type Version struct {
+	// Name is the library name, e.g. "jscan_v2.0.0".
+	Name string
+
+	// Version is the released version, "(devel)" for a local checkout
+	// or "(pinned)" for Pinned.
+	Version string
+
+	// NewValidator returns the Valid method of a reusable validator.
+	NewValidator func(preallocStackFrames int) func(src []byte) bool
+
+	// NewParser returns the Scan method of a reusable parser.
+	NewParser func(preallocStackFrames int) ScanFunc
+}
+
+// Versions are all registered versions ordered by name.
+var Versions []Version
+
+func register(v Version) {
+	if len(Versions) < 1 {
+		Versions = append(Versions, Pinned)
+	}
+	Versions = append(Versions, v)
+	sort.Slice(Versions, func(i, j int) bool {
+		return Versions[i].Name < Versions[j].Name
+	})
+}
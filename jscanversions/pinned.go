@@ -0,0 +1,31 @@
+package jscanversions
+
+import "github.com/romshark/jscan/v2"
+
+// Pinned is the version pinned in go.mod registered through the same
+// adapter as the vendored versions, which adds an indirect call per value
+// and per iterator method. It's registered along with the first vendored
+// version so that versions are compared like-for-like with each other
+// and not with the suites' direct use of the pinned version.
+var Pinned = Version{
+	Name:    "jscan_pinned",
+	Version: "(pinned)",
+	NewValidator: func(preallocStackFrames int) func(src []byte) bool {
+		return jscan.NewValidator[[]byte](preallocStackFrames).Valid
+	},
+	NewParser: func(preallocStackFrames int) ScanFunc {
+		p := jscan.NewParser[[]byte](preallocStackFrames)
+		return func(src []byte, fn func(Iterator) (err bool)) error {
+			if err := p.Scan(src, func(i *jscan.Iterator[[]byte]) (err bool) {
+				return fn(iteratorPinned{i})
+			}); err.IsErr() {
+				return err
+			}
+			return nil
+		}
+	},
+}
+
+// iteratorPinned wraps the pinned iterator
+// like the vendored versions wrap theirs.
+type iteratorPinned struct{ *jscan.Iterator[[]byte] }
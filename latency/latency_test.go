@@ -0,0 +1,56 @@
+package latency
+
+import (
+	"testing"
+
+	"github.com/romshark/jscan-benchmark/test"
+	"github.com/romshark/jscan-benchmark/validators"
+
+	"github.com/stretchr/testify/require"
+)
+
+// inputs are the request-sized inputs of the corpus whose tail latency
+// is of interest.
+var inputs = func() (l []test.Input) {
+	for _, n := range []string{"tiny_8b", "small_336b"} {
+		i, ok := test.Lookup(n)
+		if !ok {
+			panic("input " + n + " not in corpus")
+		}
+		l = append(l, i)
+	}
+	return l
+}()
+
+// BenchmarkLatency reports the latency distribution of validating
+// request-sized inputs with every library and variant of the validation
+// suite, including those validating strings.
+func BenchmarkLatency(b *testing.B) {
+	for _, bd := range inputs {
+		b.Run(bd.BenchName(), func(b *testing.B) {
+			test.SkipUnselected(b, bd)
+			src, err := bd.Source.GetJSON()
+			require.NoError(b, err)
+			tp := bd.Throughput(src)
+
+			for _, v := range validators.All {
+				if v.Supported != nil && !v.Supported() {
+					continue
+				}
+				valid := v.New()
+				test.RunLatency(b, test.Pad(v.BytesName(), 16), bd, tp, func() bool {
+					return valid(src)
+				})
+
+				if v.NewString == nil {
+					continue
+				}
+				validString := v.NewString()
+				j := string(src)
+				test.RunLatency(b, test.Pad(v.StringName(), 16), bd, tp, func() bool {
+					return validString(j)
+				})
+			}
+		})
+	}
+}
@@ -0,0 +1,9 @@
+package latency
+
+import (
+	"testing"
+
+	"github.com/romshark/jscan-benchmark/test"
+)
+
+func TestMain(m *testing.M) { test.Main(m) }
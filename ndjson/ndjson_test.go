@@ -0,0 +1,299 @@
+package ndjson
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+
+	"github.com/romshark/jscan-benchmark/jscanversions"
+	"github.com/romshark/jscan-benchmark/test"
+	"github.com/romshark/jscan-benchmark/variants"
+
+	"github.com/romshark/jscan/v2"
+
+	encodingjson "encoding/json"
+
+	jeffailgabs "github.com/Jeffail/gabs"
+	gofasterjx "github.com/go-faster/jx"
+	goccygojson "github.com/goccy/go-json"
+	miniosimdjson "github.com/minio/simdjson-go"
+	ohler55ojgoj "github.com/ohler55/ojg/oj"
+	"github.com/stretchr/testify/require"
+	tidwallgjson "github.com/tidwall/gjson"
+	valyalafastjson "github.com/valyala/fastjson"
+)
+
+// inputs are NDJSON documents, Documents is the number of records.
+var inputs = []test.Input{
+	{
+		Name:       "log_100000_14m",
+		Source:     test.SrcMake(makeLog),
+		Values:     700000,
+		Documents:  100000,
+		Categories: []string{test.CategoryLarge},
+	},
+	{
+		// The compacted small_336b corpus input on every line.
+		Name: "small_10000_1m",
+		Source: test.SrcMake(func() []byte {
+			src, err := test.SrcFile("small_336b.json").GetJSON()
+			if err != nil {
+				panic(err)
+			}
+			var b bytes.Buffer
+			if err := encodingjson.Compact(&b, src); err != nil {
+				panic(err)
+			}
+			b.WriteByte('\n')
+			return []byte(test.Repeat(b.String(), 10000))
+		}),
+		Values:     190000,
+		Documents:  10000,
+		Categories: []string{test.CategoryLarge},
+	},
+	{
+		Name:       "events_1000_435k",
+		Source:     test.SrcFile("events_1000_435k.ndjson"),
+		Values:     21509,
+		Documents:  1000,
+		Categories: []string{test.CategoryLarge, test.CategoryStringHeavy},
+	},
+}
+
+// makeLog returns log records of 7 values each.
+func makeLog() []byte {
+	levels := []string{"debug", "info", "info", "info", "warn", "error"}
+	var b bytes.Buffer
+	for i := 0; i < 100000; i++ {
+		fmt.Fprintf(&b, `{"time":"2023-06-01T%02d:%02d:%02d.%03dZ","level":%q,`+
+			`"msg":"request served","path":"/api/v1/items/%d","status":%d,"duration_ms":%d.%03d}`+"\n",
+			i/3600%24, i/60%60, i%60, i*7%1000, levels[i%len(levels)],
+			i*31%1000, 200+i%7*50, i*13%500, i*17%1000)
+	}
+	return b.Bytes()
+}
+
+// library validates NDJSON, New returns a validator
+// reusing its state across calls.
+type library struct {
+	Name string
+	New  func() func(src []byte) bool
+
+	// Supported returns false if the library doesn't run on this machine,
+	// nil if it always does.
+	Supported func() bool
+
+	// AcceptsMultipleValues is true if a line containing multiple values
+	// is accepted.
+	AcceptsMultipleValues bool
+}
+
+// perLine returns a validator splitting src on newlines first
+// and validating every line using valid. The last line may be
+// followed by a newline, any other empty line is invalid.
+func perLine(valid func(line []byte) bool) func(src []byte) bool {
+	return func(src []byte) bool {
+		for len(src) > 0 {
+			line := src
+			if i := bytes.IndexByte(src, '\n'); i >= 0 {
+				line, src = src[:i], src[i+1:]
+			} else {
+				src = nil
+			}
+			if !valid(line) {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+var libraries = func() (l []library) {
+	for _, v := range variants.JscanStack {
+		v := v
+		l = append(l, library{Name: v.Library("jscan"), New: func() func([]byte) bool {
+			return perLine(jscan.NewValidator[[]byte](v.Config).Valid)
+		}})
+	}
+
+	// Scans the values of the whole buffer one after the other checking
+	// only that every value is followed by the end of the line.
+	// Unlike splitting on newlines first it accepts empty lines
+	// and values spanning multiple lines.
+	l = append(l, library{Name: "jscan_multi", New: func() func([]byte) bool {
+		jv := jscan.NewValidator[[]byte](1024)
+		return func(src []byte) bool {
+			for len(src) > 0 {
+				t, err := jv.ValidateOne(src)
+				if err.IsErr() {
+					return false
+				}
+				i := 0
+				for i < len(t) && (t[i] == ' ' || t[i] == '\t' || t[i] == '\r') {
+					i++
+				}
+				switch {
+				case i == len(t):
+					return true
+				case t[i] != '\n':
+					return false
+				}
+				src = t[i+1:]
+			}
+			return true
+		}
+	}})
+
+	for _, v := range jscanversions.Versions {
+		v := v
+		l = append(l, library{Name: v.Name, New: func() func([]byte) bool {
+			return perLine(v.NewValidator(1024))
+		}})
+	}
+
+	l = append(l, library{Name: "encoding_json", New: func() func([]byte) bool {
+		return perLine(encodingjson.Valid)
+	}})
+
+	for _, v := range variants.Jsoniter {
+		v := v
+		l = append(l, library{Name: v.Library("jsoniter"), New: func() func([]byte) bool {
+			return perLine(v.Config.Valid)
+		}, AcceptsMultipleValues: true})
+	}
+
+	l = append(l, library{Name: "gofaster_jx", New: func() func([]byte) bool {
+		d := new(gofasterjx.Decoder)
+		return perLine(func(line []byte) bool {
+			d.ResetBytes(line)
+			return d.Validate() == nil
+		})
+	}}, library{Name: "tidwall_gjson", New: func() func([]byte) bool {
+		return perLine(tidwallgjson.ValidBytes)
+	}}, library{Name: "valyala_fastjson", New: func() func([]byte) bool {
+		return perLine(func(line []byte) bool {
+			return valyalafastjson.ValidateBytes(line) == nil
+		})
+	}}, library{Name: "goccy_go_json", New: func() func([]byte) bool {
+		return perLine(goccygojson.Valid)
+	}})
+
+	for _, v := range variants.Sonic {
+		v := v
+		l = append(l, library{Name: v.Library("bytedance_sonic"), New: func() func([]byte) bool {
+			return perLine(v.Config.Valid)
+		}})
+	}
+
+	l = append(l, library{Name: "ohler55_ojg_oj", New: func() func([]byte) bool {
+		v := &ohler55ojgoj.Validator{OnlyOne: true}
+		return perLine(func(line []byte) bool { return v.Validate(line) == nil })
+	}}, library{Name: "minio_simdjson", Supported: miniosimdjson.SupportedCPU,
+		New: func() func([]byte) bool {
+			var pj *miniosimdjson.ParsedJson
+			return perLine(func(line []byte) bool {
+				var err error
+				pj, err = miniosimdjson.Parse(line, pj)
+				return err == nil
+			})
+		},
+	}, library{
+		// Parses all records of the whole buffer at once.
+		// Like jscan_multi it accepts empty lines.
+		Name: "minio_simdjson_multi", Supported: miniosimdjson.SupportedCPU,
+		New: func() func([]byte) bool {
+			var pj *miniosimdjson.ParsedJson
+			return func(src []byte) bool {
+				var err error
+				pj, err = miniosimdjson.ParseND(src, pj)
+				return err == nil
+			}
+		},
+	}, library{Name: "jeffail_gabs", New: func() func([]byte) bool {
+		return perLine(func(line []byte) bool {
+			_, err := jeffailgabs.ParseJSON(line)
+			return err == nil
+		})
+	}})
+	return l
+}()
+
+func TestInputs(t *testing.T) {
+	for _, in := range inputs {
+		t.Run(in.Name, func(t *testing.T) {
+			src, err := in.Source.GetJSON()
+			require.NoError(t, err)
+			require.True(t, bytes.HasSuffix(src, []byte("\n")))
+			lines := bytes.Split(src[:len(src)-1], []byte("\n"))
+			require.Len(t, lines, in.Documents)
+			values := 0
+			for _, line := range lines {
+				n := test.CountValues(line)
+				require.NotZero(t, n, "invalid record %q", line)
+				values += n
+			}
+			require.Equal(t, in.Values, values)
+			require.True(t, in.HasCategory(test.SizeCategory(len(src))),
+				"expected size category %q", test.SizeCategory(len(src)))
+		})
+	}
+}
+
+func TestValid(t *testing.T) {
+	for _, l := range libraries {
+		t.Run(test.Pad(l.Name, 16), func(t *testing.T) {
+			if l.Supported != nil && !l.Supported() {
+				t.Skip("unsupported CPU")
+			}
+			valid := l.New()
+			// minio/simdjson-go doesn't support scalars at the top level.
+			for _, c := range []struct {
+				input    string
+				valid    bool
+				multiple bool // Multiple values on a line
+			}{
+				{"{\"a\":1}\n[2]\n{\"b\":\"3\"}\n", true, false},
+				{"{\"a\":1}\n[2]\n{\"b\":\"3\"}", true, false},
+				{"{\"a\":1} \r\n[2]\t\n", true, false},
+				{"{\"a\":1}\n[2,]\n{\"b\":\"3\"}\n", false, false},
+				{"{\"a\":1}\n[2}\n{\"b\":\"3\"}\n", false, false},
+				{"{\"a\":1}\n[2] [3]\n", false, true},
+				{"{\"a\":1}\n[2]\n{\"b\":3]", false, false},
+			} {
+				if c.multiple && l.AcceptsMultipleValues {
+					continue
+				}
+				require.Equal(t, c.valid, valid([]byte(c.input)), "%q", c.input)
+			}
+		})
+	}
+}
+
+var GB bool
+
+// BenchmarkValidNDJSON measures validating every record of NDJSON inputs.
+// Records per second are reported as documents per second.
+func BenchmarkValidNDJSON(b *testing.B) {
+	for _, bd := range inputs {
+		b.Run(bd.BenchName(), func(b *testing.B) {
+			test.SkipUnselected(b, bd)
+			src, err := bd.Source.GetJSON()
+			require.NoError(b, err)
+			tp := bd.Throughput(src)
+
+			for _, l := range libraries {
+				test.Run(b, test.Pad(l.Name, 16), tp, func(b *testing.B) {
+					if l.Supported != nil && !l.Supported() {
+						b.Skip("unsupported CPU")
+					}
+					valid := l.New()
+					bd.Verify(b, valid(src))
+					test.ResetTimer(b)
+					for i := 0; i < b.N; i++ {
+						GB = valid(src)
+					}
+				})
+			}
+		})
+	}
+}
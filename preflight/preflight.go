@@ -0,0 +1,377 @@
+// Package preflight checks the benchmark host for common sources of noise
+package preflight
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/klauspost/cpuid/v2"
+)
+
+// Status is the outcome of a check.
+type Status int8
+
+const (
+	StatusOK Status = iota
+	StatusWarn
+	StatusSkipped
+)
+
+func (s Status) String() string {
+	switch s {
+	case StatusOK:
+		return "ok"
+	case StatusWarn:
+		return "warn"
+	}
+	return "skip"
+}
+
+// Finding is the result of a single check.
+type Finding struct {
+	Check   string
+	Status  Status
+	Message string
+	// Advice explains how to fix a warning.
+	Advice string
+}
+
+// Defaults of Config.
+const (
+	DefaultMaxLoad   = 1.0
+	DefaultMinMemory = 1 << 30 // 1 GiB
+)
+
+// Config configures the checks.
+// Zero values are replaced with the defaults.
+type Config struct {
+	// Root is the root of the file system containing /proc and /sys,
+	// "/" by default.
+	Root string
+
+	// MaxLoad is the maximum acceptable 1-minute load average.
+	MaxLoad float64
+
+	// MinMemory is the minimum amount of available memory in bytes.
+	// The large_26m corpus alone is decompressed to 26 MB and some
+	// libraries allocate several times the input size per operation.
+	MinMemory uint64
+
+	// Hypervisor reports whether the CPUID hypervisor bit is set.
+	// Defaults to reading CPUID.
+	Hypervisor func() bool
+}
+
+func (c Config) withDefaults() Config {
+	if c.Root == "" {
+		c.Root = "/"
+	}
+	if c.MaxLoad <= 0 {
+		c.MaxLoad = DefaultMaxLoad
+	}
+	if c.MinMemory == 0 {
+		c.MinMemory = DefaultMinMemory
+	}
+	if c.Hypervisor == nil {
+		c.Hypervisor = cpuid.CPU.VM
+	}
+	return c
+}
+
+// Run runs all checks. Checks that aren't supported on the current
+// platform are skipped.
+func Run(c Config) []Finding {
+	c = c.withDefaults()
+	checks := []func(Config) Finding{checkHypervisor}
+	if runtime.GOOS == "linux" {
+		checks = append(checks,
+			checkGovernor,
+			checkBoost,
+			checkLoad,
+			checkMemory,
+			checkSwap,
+		)
+	}
+	f := make([]Finding, len(checks))
+	for i, check := range checks {
+		f[i] = check(c)
+	}
+	return f
+}
+
+// Warnings returns only the findings with StatusWarn.
+func Warnings(f []Finding) []Finding {
+	var l []Finding
+	for _, x := range f {
+		if x.Status == StatusWarn {
+			l = append(l, x)
+		}
+	}
+	return l
+}
+
+// Write writes a human-readable report of findings to w.
+func Write(w io.Writer, findings []Finding) error {
+	var b bytes.Buffer
+	for _, f := range findings {
+		fmt.Fprintf(&b, "[%s] %s: %s\n", f.Status, f.Check, f.Message)
+		if f.Status == StatusWarn && f.Advice != "" {
+			fmt.Fprintf(&b, "       %s\n", f.Advice)
+		}
+	}
+	_, err := w.Write(b.Bytes())
+	return err
+}
+
+// Gate modes.
+const (
+	GateOff  = "off"
+	GateWarn = "warn"
+	GateFail = "fail"
+)
+
+// Gate runs all checks according to mode and writes warnings to w.
+// Returns an error if mode is GateFail and there are warnings.
+func Gate(w io.Writer, mode string, c Config) error {
+	switch mode {
+	case GateOff:
+		return nil
+	case GateWarn, GateFail:
+	default:
+		return fmt.Errorf("invalid preflight mode %q", mode)
+	}
+	warn := Warnings(Run(c))
+	if len(warn) < 1 {
+		return nil
+	}
+	if err := Write(w, warn); err != nil {
+		return err
+	}
+	if mode == GateFail {
+		return fmt.Errorf("preflight: %d warnings", len(warn))
+	}
+	return nil
+}
+
+func (c Config) read(path string) (string, error) {
+	b, err := os.ReadFile(filepath.Join(c.Root, path))
+	return strings.TrimSpace(string(b)), err
+}
+
+func checkHypervisor(c Config) Finding {
+	f := Finding{Check: "hypervisor"}
+	var vendor []string
+	for _, p := range []string{
+		"sys/class/dmi/id/sys_vendor",
+		"sys/class/dmi/id/product_name",
+	} {
+		if s, err := c.read(p); err == nil && s != "" {
+			vendor = append(vendor, s)
+		}
+	}
+	dmi := strings.Join(vendor, " ")
+	vm := c.Hypervisor()
+	if !vm {
+		for _, x := range []string{
+			"KVM", "QEMU", "VMware", "VirtualBox", "Xen", "Bochs",
+			"Amazon EC2", "Google Compute Engine", "Virtual Machine",
+		} {
+			if strings.Contains(dmi, x) {
+				vm = true
+				break
+			}
+		}
+	}
+	if !vm {
+		f.Message = "no hypervisor detected"
+		return f
+	}
+	f.Status = StatusWarn
+	f.Message = "running under a hypervisor"
+	if dmi != "" {
+		f.Message += " (" + dmi + ")"
+	}
+	f.Advice = "run on a bare-metal host, virtualized results are not comparable"
+	return f
+}
+
+func checkGovernor(c Config) Finding {
+	f := Finding{Check: "cpu governor"}
+	paths, _ := filepath.Glob(filepath.Join(
+		c.Root, "sys/devices/system/cpu/cpu[0-9]*/cpufreq/scaling_governor",
+	))
+	if len(paths) < 1 {
+		f.Status = StatusSkipped
+		f.Message = "cpufreq not available"
+		return f
+	}
+	count := map[string]int{}
+	for _, p := range paths {
+		b, err := os.ReadFile(p)
+		if err != nil {
+			continue
+		}
+		count[strings.TrimSpace(string(b))]++
+	}
+	var other []string
+	for g, n := range count {
+		if g != "performance" {
+			other = append(other, fmt.Sprintf("%s (%d cpus)", g, n))
+		}
+	}
+	sort.Strings(other)
+	if len(other) < 1 {
+		f.Message = "performance"
+		return f
+	}
+	f.Status = StatusWarn
+	f.Message = "scaling governor is " + strings.Join(other, ", ")
+	f.Advice = "run `cpupower frequency-set -g performance`"
+	return f
+}
+
+func checkBoost(c Config) Finding {
+	f := Finding{Check: "turbo boost"}
+	if s, err := c.read("sys/devices/system/cpu/intel_pstate/no_turbo"); err == nil {
+		if s == "1" {
+			f.Message = "disabled (intel_pstate)"
+			return f
+		}
+		f.Status = StatusWarn
+		f.Message = "enabled (intel_pstate)"
+		f.Advice = "run `echo 1 | sudo tee /sys/devices/system/cpu/intel_pstate/no_turbo`"
+		return f
+	}
+	if s, err := c.read("sys/devices/system/cpu/cpufreq/boost"); err == nil {
+		if s == "0" {
+			f.Message = "disabled"
+			return f
+		}
+		f.Status = StatusWarn
+		f.Message = "enabled"
+		f.Advice = "run `echo 0 | sudo tee /sys/devices/system/cpu/cpufreq/boost`"
+		return f
+	}
+	f.Status = StatusSkipped
+	f.Message = "boost control not available"
+	return f
+}
+
+func checkLoad(c Config) Finding {
+	f := Finding{Check: "load average"}
+	s, err := c.read("proc/loadavg")
+	if err != nil {
+		f.Status = StatusSkipped
+		f.Message = err.Error()
+		return f
+	}
+	fields := strings.Fields(s)
+	var l float64
+	if len(fields) > 0 {
+		l, err = strconv.ParseFloat(fields[0], 64)
+	}
+	if len(fields) < 1 || err != nil {
+		f.Status = StatusSkipped
+		f.Message = "unexpected /proc/loadavg format"
+		return f
+	}
+	f.Message = fmt.Sprintf("%.2f", l)
+	if l > c.MaxLoad {
+		f.Status = StatusWarn
+		f.Message += fmt.Sprintf(" exceeds %.2f", c.MaxLoad)
+		f.Advice = "stop other processes (graphics system, browsers, builds) before running"
+	}
+	return f
+}
+
+// meminfo returns the values of /proc/meminfo in bytes.
+func (c Config) meminfo() (map[string]uint64, error) {
+	s, err := c.read("proc/meminfo")
+	if err != nil {
+		return nil, err
+	}
+	m := map[string]uint64{}
+	sc := bufio.NewScanner(strings.NewReader(s))
+	for sc.Scan() {
+		k, v, ok := strings.Cut(sc.Text(), ":")
+		if !ok {
+			continue
+		}
+		fields := strings.Fields(v)
+		if len(fields) < 1 {
+			continue
+		}
+		n, err := strconv.ParseUint(fields[0], 10, 64)
+		if err != nil {
+			continue
+		}
+		if len(fields) > 1 && fields[1] == "kB" {
+			n *= 1024
+		}
+		m[k] = n
+	}
+	return m, nil
+}
+
+func checkMemory(c Config) Finding {
+	f := Finding{Check: "available memory"}
+	m, err := c.meminfo()
+	if err != nil {
+		f.Status = StatusSkipped
+		f.Message = err.Error()
+		return f
+	}
+	a, ok := m["MemAvailable"]
+	if !ok {
+		f.Status = StatusSkipped
+		f.Message = "MemAvailable not reported"
+		return f
+	}
+	f.Message = formatBytes(a)
+	if a < c.MinMemory {
+		f.Status = StatusWarn
+		f.Message += " is less than " + formatBytes(c.MinMemory)
+		f.Advice = "free memory, the large inputs may cause swapping and GC pressure"
+	}
+	return f
+}
+
+func checkSwap(c Config) Finding {
+	f := Finding{Check: "swap"}
+	m, err := c.meminfo()
+	if err != nil {
+		f.Status = StatusSkipped
+		f.Message = err.Error()
+		return f
+	}
+	total, free := m["SwapTotal"], m["SwapFree"]
+	if total == 0 {
+		f.Message = "no swap"
+		return f
+	}
+	used := total - free
+	f.Message = formatBytes(used) + " in use"
+	if used > 0 {
+		f.Status = StatusWarn
+		f.Advice = "run `sudo swapoff -a && sudo swapon -a` or free memory to avoid swapping"
+	}
+	return f
+}
+
+func formatBytes(b uint64) string {
+	switch {
+	case b >= 1<<30:
+		return fmt.Sprintf("%.1f GiB", float64(b)/(1<<30))
+	case b >= 1<<20:
+		return fmt.Sprintf("%.1f MiB", float64(b)/(1<<20))
+	}
+	return fmt.Sprintf("%d KiB", b/1024)
+}
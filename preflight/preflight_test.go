@@ -0,0 +1,108 @@
+package preflight_test
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/romshark/jscan-benchmark/preflight"
+
+	"github.com/stretchr/testify/require"
+)
+
+func writeFiles(t *testing.T, files map[string]string) string {
+	t.Helper()
+	root := t.TempDir()
+	for p, c := range files {
+		p = filepath.Join(root, p)
+		require.NoError(t, os.MkdirAll(filepath.Dir(p), 0o755))
+		require.NoError(t, os.WriteFile(p, []byte(c), 0o644))
+	}
+	return root
+}
+
+func findings(f []preflight.Finding) map[string]preflight.Status {
+	m := make(map[string]preflight.Status, len(f))
+	for _, x := range f {
+		m[x.Check] = x.Status
+	}
+	return m
+}
+
+func TestRun(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("checks require linux")
+	}
+
+	t.Run("quiet", func(t *testing.T) {
+		root := writeFiles(t, map[string]string{
+			"sys/devices/system/cpu/cpu0/cpufreq/scaling_governor": "performance\n",
+			"sys/devices/system/cpu/cpu1/cpufreq/scaling_governor": "performance\n",
+			"sys/devices/system/cpu/intel_pstate/no_turbo":         "1\n",
+			"sys/class/dmi/id/sys_vendor":                          "Dell Inc.\n",
+			"proc/loadavg":                                         "0.05 0.10 0.12 1/300 1234\n",
+			"proc/meminfo": "MemTotal: 16384000 kB\n" +
+				"MemAvailable: 8192000 kB\n" +
+				"SwapTotal: 0 kB\n" +
+				"SwapFree: 0 kB\n",
+		})
+		f := preflight.Run(preflight.Config{
+			Root:       root,
+			Hypervisor: func() bool { return false },
+		})
+		require.Equal(t, map[string]preflight.Status{
+			"hypervisor":       preflight.StatusOK,
+			"cpu governor":     preflight.StatusOK,
+			"turbo boost":      preflight.StatusOK,
+			"load average":     preflight.StatusOK,
+			"available memory": preflight.StatusOK,
+			"swap":             preflight.StatusOK,
+		}, findings(f))
+		require.Empty(t, preflight.Warnings(f))
+	})
+
+	t.Run("noisy", func(t *testing.T) {
+		root := writeFiles(t, map[string]string{
+			"sys/devices/system/cpu/cpu0/cpufreq/scaling_governor": "performance\n",
+			"sys/devices/system/cpu/cpu1/cpufreq/scaling_governor": "powersave\n",
+			"sys/devices/system/cpu/cpufreq/boost":                 "1\n",
+			"sys/class/dmi/id/sys_vendor":                          "QEMU\n",
+			"proc/loadavg":                                         "3.50 2.10 1.12 4/300 1234\n",
+			"proc/meminfo": "MemTotal: 1024000 kB\n" +
+				"MemAvailable: 512000 kB\n" +
+				"SwapTotal: 1024000 kB\n" +
+				"SwapFree: 1000000 kB\n",
+		})
+		f := preflight.Run(preflight.Config{
+			Root:       root,
+			Hypervisor: func() bool { return false },
+		})
+		require.Equal(t, map[string]preflight.Status{
+			"hypervisor":       preflight.StatusWarn,
+			"cpu governor":     preflight.StatusWarn,
+			"turbo boost":      preflight.StatusWarn,
+			"load average":     preflight.StatusWarn,
+			"available memory": preflight.StatusWarn,
+			"swap":             preflight.StatusWarn,
+		}, findings(f))
+		for _, w := range preflight.Warnings(f) {
+			require.NotEmpty(t, w.Advice, w.Check)
+		}
+	})
+
+	t.Run("unavailable", func(t *testing.T) {
+		f := preflight.Run(preflight.Config{
+			Root:       t.TempDir(),
+			Hypervisor: func() bool { return true },
+		})
+		require.Equal(t, map[string]preflight.Status{
+			"hypervisor":       preflight.StatusWarn,
+			"cpu governor":     preflight.StatusSkipped,
+			"turbo boost":      preflight.StatusSkipped,
+			"load average":     preflight.StatusSkipped,
+			"available memory": preflight.StatusSkipped,
+			"swap":             preflight.StatusSkipped,
+		}, findings(f))
+	})
+}
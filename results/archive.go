@@ -0,0 +1,55 @@
+package results
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+)
+
+// Archive is a set of results annotated with the machine and date
+// they were recorded on.
+type Archive struct {
+	Machine string    `json:"machine"`
+	Date    time.Time `json:"date"`
+	Set
+}
+
+// ID returns a human-readable identifier of the archive.
+func (a *Archive) ID() string {
+	if a.Date.IsZero() {
+		return a.Machine
+	}
+	return a.Machine + " " + a.Date.Format(time.DateOnly)
+}
+
+// WriteFile writes the archive as JSON to path.
+func (a *Archive) WriteFile(path string) error {
+	b, err := json.MarshalIndent(a, "", "\t")
+	if err != nil {
+		return fmt.Errorf("encoding archive: %w", err)
+	}
+	return os.WriteFile(path, append(b, '\n'), 0o644)
+}
+
+// ReadFile reads either a JSON archive or the output of `go test -bench`
+// from path. Results read from benchmark output have no metadata.
+func ReadFile(path string) (*Archive, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if t := bytes.TrimSpace(b); len(t) > 0 && t[0] == '{' {
+		a := new(Archive)
+		if err := json.Unmarshal(b, a); err != nil {
+			return nil, fmt.Errorf("decoding archive %q: %w", path, err)
+		}
+		return a, nil
+	}
+	s, err := Parse(bytes.NewReader(b))
+	if err != nil {
+		return nil, fmt.Errorf("parsing %q: %w", path, err)
+	}
+	return &Archive{Set: *s}, nil
+}
@@ -0,0 +1,107 @@
+package results
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strings"
+)
+
+// Comparison is the mean ns/op of a case in two sets.
+type Comparison struct {
+	Key
+	Base float64
+	New  float64
+}
+
+// Speedup returns Base/New, greater than 1 if New is faster.
+func (c Comparison) Speedup() float64 { return c.Base / c.New }
+
+// Compare returns the comparisons of all cases with ns/op results
+// in both sets in order of first appearance in base.
+func Compare(base, new *Set) []Comparison {
+	means := map[Key]float64{}
+	for _, c := range new.Cases() {
+		if m, ok := c.Mean(UnitNsPerOp); ok {
+			means[c.Key] = m
+		}
+	}
+	var l []Comparison
+	for _, c := range base.Cases() {
+		b, ok := c.Mean(UnitNsPerOp)
+		n, okNew := means[c.Key]
+		if ok && okNew && b > 0 && n > 0 {
+			l = append(l, Comparison{Key: c.Key, Base: b, New: n})
+		}
+	}
+	return l
+}
+
+// LibrarySpeedup is the geometric-mean speedup of a library
+// across all compared inputs.
+type LibrarySpeedup struct {
+	Library string
+	Speedup float64
+	Inputs  int
+}
+
+// SpeedupByLibrary returns the geometric-mean speedup of each library
+// sorted in descending order.
+func SpeedupByLibrary(c []Comparison) []LibrarySpeedup {
+	logSum := map[string]float64{}
+	inputs := map[string]int{}
+	for _, x := range c {
+		logSum[x.Library] += math.Log(x.Speedup())
+		inputs[x.Library]++
+	}
+	l := make([]LibrarySpeedup, 0, len(logSum))
+	for lib, s := range logSum {
+		l = append(l, LibrarySpeedup{
+			Library: lib,
+			Speedup: math.Exp(s / float64(inputs[lib])),
+			Inputs:  inputs[lib],
+		})
+	}
+	sort.Slice(l, func(i, j int) bool {
+		if l[i].Speedup != l[j].Speedup {
+			return l[i].Speedup > l[j].Speedup
+		}
+		return l[i].Library < l[j].Library
+	})
+	return l
+}
+
+// WriteComparison writes a markdown table of the speedup of each library
+// followed by a table per suite comparing the ns/op of every case to w.
+// base and new name the compared sets in the table headers.
+func WriteComparison(w io.Writer, base, new string, c []Comparison) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "### Speedup of %s over %s\n\n|library|speedup|inputs|\n|-|-:|-:|\n",
+		new, base)
+	for _, l := range SpeedupByLibrary(c) {
+		fmt.Fprintf(&b, "|%s|%s|%d|\n", l.Library, FormatSpeedup(l.Speedup), l.Inputs)
+	}
+	b.WriteByte('\n')
+
+	var suites []string
+	bySuite := map[string][]Comparison{}
+	for _, x := range c {
+		if _, ok := bySuite[x.Suite]; !ok {
+			suites = append(suites, x.Suite)
+		}
+		bySuite[x.Suite] = append(bySuite[x.Suite], x)
+	}
+	for _, suite := range suites {
+		fmt.Fprintf(&b, "### %s\n\n|input|library|%s ns/op|%s ns/op|speedup|\n|-|-|-:|-:|-:|\n",
+			suite, base, new)
+		for _, x := range bySuite[suite] {
+			fmt.Fprintf(&b, "|%s|%s|%s|%s|%s|\n", x.Input, x.Library,
+				FormatValue(UnitNsPerOp, x.Base), FormatValue(UnitNsPerOp, x.New),
+				FormatSpeedup(x.Speedup()))
+		}
+		b.WriteByte('\n')
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
@@ -0,0 +1,40 @@
+package results_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/romshark/jscan-benchmark/results"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompare(t *testing.T) {
+	base, err := results.Parse(strings.NewReader(`pkg: x/validation
+BenchmarkValid/tiny/jscan 1 10 ns/op
+BenchmarkValid/tiny/other 1 20 ns/op
+BenchmarkValid/large/jscan 1 100 ns/op
+BenchmarkValid/large/other 1 50 ns/op
+BenchmarkValid/missing/other 1 50 ns/op
+`))
+	require.NoError(t, err)
+	pgo, err := results.Parse(strings.NewReader(`pkg: x/validation
+BenchmarkValid/tiny/jscan 1 5 ns/op
+BenchmarkValid/tiny/other 1 20 ns/op
+BenchmarkValid/large/jscan 1 50 ns/op
+BenchmarkValid/large/other 1 100 ns/op
+`))
+	require.NoError(t, err)
+
+	c := results.Compare(base, pgo)
+	require.Len(t, c, 4)
+	require.Equal(t, results.Key{Suite: "validation", Input: "tiny", Library: "jscan"}, c[0].Key)
+	require.InDelta(t, 2, c[0].Speedup(), 1e-9)
+
+	s := results.SpeedupByLibrary(c)
+	require.Equal(t, []results.LibrarySpeedup{
+		{Library: "jscan", Speedup: 2, Inputs: 2},
+		// sqrt(1 * 0.5)
+		{Library: "other", Speedup: 0.7071067811865476, Inputs: 2},
+	}, s)
+}
@@ -0,0 +1,197 @@
+package results
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+)
+
+// Default noise diagnostic thresholds.
+const (
+	DefaultMaxCV      = 0.05
+	DefaultMinSamples = 3
+	DefaultDriftTau   = 0.7
+)
+
+// Diagnostics configures the noise diagnostics of cases.
+// Zero values are replaced with the defaults.
+type Diagnostics struct {
+	// MaxCV is the maximum coefficient of variation of ns/op
+	// of a trustworthy case.
+	MaxCV float64
+
+	// MinSamples is the minimum number of samples
+	// required to assess the quality of a case.
+	MinSamples int
+
+	// DriftTau is the minimum absolute Kendall rank correlation
+	// between sample order and ns/op considered a monotonic drift.
+	DriftTau float64
+}
+
+func (d Diagnostics) withDefaults() Diagnostics {
+	if d.MaxCV <= 0 {
+		d.MaxCV = DefaultMaxCV
+	}
+	if d.MinSamples <= 0 {
+		d.MinSamples = DefaultMinSamples
+	}
+	if d.DriftTau <= 0 {
+		d.DriftTau = DefaultDriftTau
+	}
+	return d
+}
+
+// Quality is the result of the noise diagnostics of a case.
+type Quality struct {
+	Samples int
+	// CV is the coefficient of variation of ns/op.
+	CV float64
+	// Bimodal is true if ns/op samples form two clusters.
+	Bimodal bool
+	// Drift is true if ns/op samples monotonically increase or decrease
+	// over time, which is typical for thermal throttling.
+	Drift bool
+	// Insufficient is true if there are too few samples to assess quality.
+	Insufficient bool
+}
+
+// Noisy returns true if the samples of the case are noisy.
+// Cases with insufficient samples can't be assessed and aren't noisy.
+func (q Quality) Noisy(d Diagnostics) bool {
+	d = d.withDefaults()
+	return !q.Insufficient && (q.CV > d.MaxCV || q.Bimodal || q.Drift)
+}
+
+// Trusted returns true if the case has sufficient samples
+// and isn't noisy.
+func (q Quality) Trusted(d Diagnostics) bool {
+	return !q.Insufficient && !q.Noisy(d)
+}
+
+// Verdict returns a short human-readable verdict.
+func (q Quality) Verdict(d Diagnostics) string {
+	d = d.withDefaults()
+	if q.Insufficient {
+		return fmt.Sprintf("insufficient samples (n=%d)", q.Samples)
+	}
+	var r []string
+	if q.CV > d.MaxCV {
+		r = append(r, fmt.Sprintf("cv %.1f%%", q.CV*100))
+	}
+	if q.Bimodal {
+		r = append(r, "bimodal")
+	}
+	if q.Drift {
+		r = append(r, "drift")
+	}
+	if len(r) < 1 {
+		return "ok"
+	}
+	return "noisy: " + strings.Join(r, ", ")
+}
+
+// Diagnose computes the quality of c.
+func (d Diagnostics) Diagnose(c Case) Quality {
+	d = d.withDefaults()
+	v := c.Values(UnitNsPerOp)
+	q := Quality{Samples: len(v)}
+	if len(v) < d.MinSamples || len(v) < 2 {
+		q.Insufficient = true
+		return q
+	}
+	m, sd := meanStddev(v)
+	if m > 0 {
+		q.CV = sd / m
+	}
+	q.Bimodal = isBimodal(v, d.MaxCV)
+	q.Drift = len(v) >= 5 && math.Abs(kendallTau(v)) >= d.DriftTau &&
+		relativeShift(v) > d.MaxCV/2
+	return q
+}
+
+// Untrusted returns the cases of set that are noisy
+// or have insufficient samples.
+func (d Diagnostics) Untrusted(set *Set) []Case {
+	var l []Case
+	for _, c := range set.Cases() {
+		if !d.Diagnose(c).Trusted(d) {
+			l = append(l, c)
+		}
+	}
+	return l
+}
+
+func mean(v []float64) (m float64) {
+	for _, x := range v {
+		m += x
+	}
+	return m / float64(len(v))
+}
+
+// meanStddev returns the mean and sample standard deviation of v,
+// v must contain at least 2 values.
+func meanStddev(v []float64) (m, sd float64) {
+	m = mean(v)
+	for _, x := range v {
+		sd += (x - m) * (x - m)
+	}
+	return m, math.Sqrt(sd / float64(len(v)-1))
+}
+
+// isBimodal returns true if the sorted samples split into two clusters
+// of at least two samples each separated by a gap that is both larger
+// than maxSpread of the median and larger than the spread within
+// either cluster.
+func isBimodal(v []float64, maxSpread float64) bool {
+	if len(v) < 4 {
+		return false
+	}
+	s := append([]float64(nil), v...)
+	sort.Float64s(s)
+	median := s[len(s)/2]
+	gap, at := 0.0, 0
+	for i := 2; i <= len(s)-2; i++ {
+		if g := s[i] - s[i-1]; g > gap {
+			gap, at = g, i
+		}
+	}
+	if at == 0 || gap <= maxSpread*median {
+		return false
+	}
+	lo, hi := s[at-1]-s[0], s[len(s)-1]-s[at]
+	return gap > lo && gap > hi
+}
+
+// kendallTau returns the Kendall rank correlation between
+// the sample order and the sample values.
+func kendallTau(v []float64) float64 {
+	var s int
+	for i := 0; i < len(v); i++ {
+		for j := i + 1; j < len(v); j++ {
+			switch {
+			case v[j] > v[i]:
+				s++
+			case v[j] < v[i]:
+				s--
+			}
+		}
+	}
+	n := len(v)
+	return float64(s) / float64(n*(n-1)/2)
+}
+
+// relativeShift returns the relative difference between the mean
+// of the first and the last third of the samples.
+func relativeShift(v []float64) float64 {
+	k := len(v) / 3
+	if k < 1 {
+		return 0
+	}
+	first, last := mean(v[:k]), mean(v[len(v)-k:])
+	if first <= 0 {
+		return 0
+	}
+	return math.Abs(last-first) / first
+}
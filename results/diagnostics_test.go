@@ -0,0 +1,92 @@
+package results_test
+
+import (
+	"testing"
+
+	"github.com/romshark/jscan-benchmark/results"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiagnose(t *testing.T) {
+	c := func(v ...float64) results.Case {
+		var c results.Case
+		for _, x := range v {
+			c.Samples = append(c.Samples, results.Result{
+				Metrics: map[string]float64{results.UnitNsPerOp: x},
+			})
+		}
+		return c
+	}
+	var d results.Diagnostics
+
+	for _, td := range []struct {
+		name    string
+		samples results.Case
+		expect  results.Quality
+		noisy   bool
+		trusted bool
+		verdict string
+	}{
+		{
+			name:    "insufficient",
+			samples: c(100, 200),
+			expect:  results.Quality{Samples: 2, Insufficient: true},
+			verdict: "insufficient samples (n=2)",
+		},
+		{
+			name:    "single",
+			samples: c(100),
+			expect:  results.Quality{Samples: 1, Insufficient: true},
+			verdict: "insufficient samples (n=1)",
+		},
+		{
+			name:    "stable",
+			samples: c(100, 101, 99, 100, 100, 101),
+			expect:  results.Quality{Samples: 6},
+			trusted: true,
+			verdict: "ok",
+		},
+		{
+			name:    "bimodal",
+			samples: c(100, 130, 101, 131, 100, 130),
+			expect:  results.Quality{Samples: 6, Bimodal: true},
+			noisy:   true,
+			verdict: "noisy: cv 14.3%, bimodal",
+		},
+		{
+			name:    "drift",
+			samples: c(100, 102, 104, 106, 108, 110),
+			expect:  results.Quality{Samples: 6, Drift: true},
+			noisy:   true,
+			verdict: "noisy: drift",
+		},
+	} {
+		t.Run(td.name, func(t *testing.T) {
+			q := d.Diagnose(td.samples)
+			require.Equal(t, td.noisy, q.Noisy(d))
+			require.Equal(t, td.trusted, q.Trusted(d))
+			require.Equal(t, td.verdict, q.Verdict(d))
+			q.CV = 0 // Ignore CV
+			require.Equal(t, td.expect, q)
+		})
+	}
+}
+
+func TestUntrusted(t *testing.T) {
+	r := func(input string, nsPerOp float64) results.Result {
+		return results.Result{
+			Key:     results.Key{Suite: "validation", Input: input, Library: "jscan"},
+			Metrics: map[string]float64{results.UnitNsPerOp: nsPerOp},
+		}
+	}
+	s := &results.Set{Results: []results.Result{
+		r("stable", 100), r("stable", 101), r("stable", 100),
+		r("single", 100),
+	}}
+	var keys []string
+	for _, c := range (results.Diagnostics{}).Untrusted(s) {
+		keys = append(keys, c.Key.String())
+	}
+	require.Equal(t, []string{"validation/single/jscan"}, keys)
+}
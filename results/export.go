@@ -0,0 +1,209 @@
+package results
+
+import (
+	"bufio"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// WriteCSV writes one row per case of all archives to w.
+// Metadata columns are followed by the mean of every unit
+// reported by any of the cases.
+func WriteCSV(w io.Writer, archives ...*Archive) error {
+	var all []Case
+	for _, a := range archives {
+		all = append(all, a.Cases()...)
+	}
+	units := UnitsOf(all)
+
+	cw := csv.NewWriter(w)
+	header := []string{
+		"machine", "date", "goos", "goarch", "cpu",
+		"suite", "input", "library", "samples",
+	}
+	if err := cw.Write(append(header, units...)); err != nil {
+		return err
+	}
+	for _, a := range archives {
+		date := ""
+		if !a.Date.IsZero() {
+			date = a.Date.Format(time.RFC3339)
+		}
+		for _, c := range a.Cases() {
+			row := []string{
+				a.Machine, date, a.Goos, a.Goarch, a.CPU,
+				c.Suite, c.Input, c.Library, strconv.Itoa(len(c.Samples)),
+			}
+			for _, u := range units {
+				v := ""
+				if m, ok := c.Mean(u); ok {
+					v = strconv.FormatFloat(m, 'g', -1, 64)
+				}
+				row = append(row, v)
+			}
+			if err := cw.Write(row); err != nil {
+				return err
+			}
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// WriteBenchstat writes all samples of the archive in the format of
+// `go test -bench` accepted by benchstat. The machine and date of the
+// archive are written as additional configuration keys.
+func WriteBenchstat(w io.Writer, a *Archive) error {
+	bw := bufio.NewWriter(w)
+	writeConfig := func(k, v string) {
+		if v != "" {
+			fmt.Fprintf(bw, "%s: %s\n", k, v)
+		}
+	}
+	writeConfig("machine", a.Machine)
+	if !a.Date.IsZero() {
+		writeConfig("date", a.Date.Format(time.RFC3339))
+	}
+	writeConfig("goos", a.Goos)
+	writeConfig("goarch", a.Goarch)
+	writeConfig("cpu", a.CPU)
+
+	pkg := ""
+	for _, r := range a.Results {
+		if p := r.Pkg; p != pkg && p != "" {
+			pkg = p
+			writeConfig("pkg", p)
+		}
+		name := r.Name
+		if name == "" {
+			name = "Benchmark" + r.Key.String()
+		}
+		if r.Procs > 0 {
+			name += "-" + strconv.Itoa(r.Procs)
+		}
+		fmt.Fprintf(bw, "%s\t%d", name, r.N)
+		for _, u := range unitsOfResult(r) {
+			fmt.Fprintf(bw, "\t%s %s", strconv.FormatFloat(r.Metrics[u], 'f', -1, 64), u)
+		}
+		bw.WriteByte('\n')
+	}
+	return bw.Flush()
+}
+
+func unitsOfResult(r Result) []string {
+	return UnitsOf([]Case{{Samples: []Result{r}}})
+}
+
+// MetricPrefix is the prefix of all exported OpenMetrics metric names.
+const MetricPrefix = "jscanbench_"
+
+// MetricName returns the OpenMetrics metric name of unit.
+func MetricName(unit string) string {
+	switch unit {
+	case UnitNsPerOp:
+		return MetricPrefix + "ns_per_op"
+	case UnitMBPerSec:
+		return MetricPrefix + "megabytes_per_second"
+	case UnitValuesPerSec:
+		return MetricPrefix + "values_per_second"
+	case UnitDocumentsPerSec:
+		return MetricPrefix + "documents_per_second"
+	case UnitBytesPerOp:
+		return MetricPrefix + "bytes_per_op"
+	case UnitAllocsPerOp:
+		return MetricPrefix + "allocs_per_op"
+	case UnitPeakHeapBytes:
+		return MetricPrefix + "peak_heap_bytes"
+	case UnitGCPerOp:
+		return MetricPrefix + "gc_cycles_per_op"
+	case UnitGCPauseNsPerOp:
+		return MetricPrefix + "gc_pause_ns_per_op"
+	case UnitP50Ns:
+		return MetricPrefix + "latency_p50_ns"
+	case UnitP90Ns:
+		return MetricPrefix + "latency_p90_ns"
+	case UnitP99Ns:
+		return MetricPrefix + "latency_p99_ns"
+	case UnitP999Ns:
+		return MetricPrefix + "latency_p999_ns"
+	case UnitMaxNs:
+		return MetricPrefix + "latency_max_ns"
+	}
+	var b strings.Builder
+	b.WriteString(MetricPrefix)
+	for _, r := range strings.ToLower(unit) {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9':
+			b.WriteRune(r)
+		case r == '/':
+			b.WriteString("_per_")
+		default:
+			b.WriteByte('_')
+		}
+	}
+	return b.String()
+}
+
+// OpenMetricsContentType is the content type of the output
+// of WriteOpenMetrics.
+const OpenMetricsContentType = "application/openmetrics-text; version=1.0.0; charset=utf-8"
+
+// WriteOpenMetrics writes the mean of every unit of every case of all
+// archives as OpenMetrics gauges labeled with suite, input, library,
+// machine and archive date to w. The output is also valid Prometheus
+// text format. Archives of the same machine and date would produce
+// duplicate series and are rejected.
+func WriteOpenMetrics(w io.Writer, archives ...*Archive) error {
+	type sample struct {
+		labels string
+		value  float64
+	}
+	metrics := map[string][]sample{}
+	seen := make(map[string]*Archive, len(archives))
+	for _, a := range archives {
+		date := a.Date.Format(time.RFC3339)
+		k := a.Machine + "\x00" + date
+		if p, ok := seen[k]; ok {
+			return fmt.Errorf("archives %q and %q have the same machine and date",
+				p.ID(), a.ID())
+		}
+		seen[k] = a
+		for _, c := range a.Cases() {
+			labels := fmt.Sprintf(
+				`suite="%s",input="%s",library="%s",machine="%s",date="%s"`,
+				escapeLabel(c.Suite), escapeLabel(c.Input),
+				escapeLabel(c.Library), escapeLabel(a.Machine), date,
+			)
+			for _, u := range UnitsOf([]Case{c}) {
+				m, _ := c.Mean(u)
+				name := MetricName(u)
+				metrics[name] = append(metrics[name], sample{labels, m})
+			}
+		}
+	}
+	names := make([]string, 0, len(metrics))
+	for n := range metrics {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+
+	bw := bufio.NewWriter(w)
+	for _, n := range names {
+		fmt.Fprintf(bw, "# TYPE %s gauge\n", n)
+		for _, s := range metrics[n] {
+			fmt.Fprintf(bw, "%s{%s} %s\n", n, s.labels,
+				strconv.FormatFloat(s.value, 'g', -1, 64))
+		}
+	}
+	bw.WriteString("# EOF\n")
+	return bw.Flush()
+}
+
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func escapeLabel(s string) string { return labelEscaper.Replace(s) }
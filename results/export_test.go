@@ -0,0 +1,86 @@
+package results_test
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/romshark/jscan-benchmark/results"
+
+	"github.com/stretchr/testify/require"
+)
+
+func exportArchive(t *testing.T) *results.Archive {
+	t.Helper()
+	s, err := results.Parse(strings.NewReader(`goos: linux
+goarch: amd64
+pkg: x/validation
+cpu: Some CPU
+BenchmarkValid/tiny_8b_______________/jscan___________-8 100 10 ns/op 800.00 MB/s 0 B/op 0 allocs/op
+BenchmarkValid/tiny_8b_______________/jscan___________-8 100 30 ns/op 266.67 MB/s 0 B/op 0 allocs/op
+`))
+	require.NoError(t, err)
+	return &results.Archive{
+		Machine: `m"1`,
+		Date:    time.Date(2023, 6, 1, 12, 0, 0, 0, time.UTC),
+		Set:     *s,
+	}
+}
+
+func TestWriteCSV(t *testing.T) {
+	var b bytes.Buffer
+	require.NoError(t, results.WriteCSV(&b, exportArchive(t)))
+	require.Equal(t, `machine,date,goos,goarch,cpu,suite,input,library,samples,ns/op,MB/s,B/op,allocs/op
+"m""1",2023-06-01T12:00:00Z,linux,amd64,Some CPU,validation,tiny_8b,jscan,2,20,533.335,0,0
+`, b.String())
+}
+
+func TestWriteBenchstat(t *testing.T) {
+	var b bytes.Buffer
+	require.NoError(t, results.WriteBenchstat(&b, exportArchive(t)))
+	require.Equal(t, `machine: m"1
+date: 2023-06-01T12:00:00Z
+goos: linux
+goarch: amd64
+cpu: Some CPU
+pkg: x/validation
+BenchmarkValid/tiny_8b_______________/jscan___________-8	100	10 ns/op	800 MB/s	0 B/op	0 allocs/op
+BenchmarkValid/tiny_8b_______________/jscan___________-8	100	30 ns/op	266.67 MB/s	0 B/op	0 allocs/op
+`, b.String())
+
+	// The output must be parseable as benchmark output again.
+	s, err := results.Parse(&b)
+	require.NoError(t, err)
+	require.Equal(t, exportArchive(t).Results, s.Results)
+}
+
+func TestWriteOpenMetrics(t *testing.T) {
+	var b bytes.Buffer
+	require.NoError(t, results.WriteOpenMetrics(&b, exportArchive(t)))
+	l := `{suite="validation",input="tiny_8b",library="jscan",machine="m\"1",date="2023-06-01T12:00:00Z"}`
+	require.Equal(t, `# TYPE jscanbench_allocs_per_op gauge
+jscanbench_allocs_per_op`+l+` 0
+# TYPE jscanbench_bytes_per_op gauge
+jscanbench_bytes_per_op`+l+` 0
+# TYPE jscanbench_megabytes_per_second gauge
+jscanbench_megabytes_per_second`+l+` 533.335
+# TYPE jscanbench_ns_per_op gauge
+jscanbench_ns_per_op`+l+` 20
+# EOF
+`, b.String())
+}
+
+func TestWriteOpenMetricsDuplicateSeries(t *testing.T) {
+	a, b := exportArchive(t), exportArchive(t)
+	err := results.WriteOpenMetrics(io.Discard, a, b)
+	require.EqualError(t, err,
+		`archives "m\"1 2023-06-01" and "m\"1 2023-06-01" have the same machine and date`)
+
+	// Archives of the same machine taken at different times are distinct series.
+	b.Date = b.Date.Add(time.Hour)
+	var buf bytes.Buffer
+	require.NoError(t, results.WriteOpenMetrics(&buf, a, b))
+	require.Contains(t, buf.String(), `date="2023-06-01T13:00:00Z"`)
+}
@@ -0,0 +1,119 @@
+package results
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// WriteMarkdown writes a markdown table per suite to w.
+// Multiple samples of the same case are averaged and,
+// if any case has multiple samples, diagnosed for noise.
+func WriteMarkdown(w io.Writer, s *Set, d Diagnostics) error {
+	if s.CPU != "" {
+		if _, err := fmt.Fprintf(w, "%s/%s - %s\n\n", s.Goos, s.Goarch, s.CPU); err != nil {
+			return err
+		}
+	}
+	if err := WriteVersions(w, s.Versions); err != nil {
+		return err
+	}
+	if err := WriteFailures(w, s.Failures); err != nil {
+		return err
+	}
+	cases := s.Cases()
+	for _, suite := range s.Suites() {
+		var c []Case
+		for _, x := range cases {
+			if x.Suite == suite {
+				c = append(c, x)
+			}
+		}
+		units := UnitsOf(c)
+		diagnose := false
+		for _, x := range c {
+			if len(x.Samples) > 1 {
+				diagnose = true
+				break
+			}
+		}
+
+		var b strings.Builder
+		fmt.Fprintf(&b, "### %s\n\n|input|library|", suite)
+		for _, u := range units {
+			fmt.Fprintf(&b, "%s|", u)
+		}
+		if diagnose {
+			b.WriteString("±|quality|")
+		}
+		b.WriteString("\n|-|-|")
+		for range units {
+			b.WriteString("-:|")
+		}
+		if diagnose {
+			b.WriteString("-:|-|")
+		}
+		b.WriteByte('\n')
+		for _, x := range c {
+			fmt.Fprintf(&b, "|%s|%s|", x.Input, x.Library)
+			for _, u := range units {
+				if v, ok := x.Mean(u); ok {
+					b.WriteString(FormatValue(u, v))
+				}
+				b.WriteByte('|')
+			}
+			if diagnose {
+				q := d.Diagnose(x)
+				if !q.Insufficient {
+					fmt.Fprintf(&b, "%.1f%%", q.CV*100)
+				}
+				fmt.Fprintf(&b, "|%s|", q.Verdict(d))
+			}
+			b.WriteByte('\n')
+		}
+		b.WriteByte('\n')
+		if _, err := io.WriteString(w, b.String()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// WriteFailures writes a markdown table of the failed cases to w.
+// Nothing is written if there are no failures.
+func WriteFailures(w io.Writer, f []Failure) error {
+	if len(f) < 1 {
+		return nil
+	}
+	var b strings.Builder
+	b.WriteString("### Failures\n\n|suite|input|library|message|\n|-|-|-|-|\n")
+	for _, x := range f {
+		fmt.Fprintf(&b, "|%s|%s|%s|%s|\n", x.Suite, x.Input, x.Library,
+			strings.ReplaceAll(x.Message, "|", "\\|"))
+	}
+	b.WriteByte('\n')
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// WriteVersions writes a markdown table of the module versions to w.
+// Nothing is written if there are no versions.
+func WriteVersions(w io.Writer, versions map[string]string) error {
+	if len(versions) < 1 {
+		return nil
+	}
+	paths := make([]string, 0, len(versions))
+	for p := range versions {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+	var b strings.Builder
+	b.WriteString("|package|version|\n|-|-|\n")
+	for _, p := range paths {
+		fmt.Fprintf(&b, "|%s|%s|\n", p, versions[p])
+	}
+	b.WriteByte('\n')
+	_, err := io.WriteString(w, b.String())
+	return err
+}
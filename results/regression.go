@@ -0,0 +1,145 @@
+package results
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// DefaultThresholds are the maximum tolerated relative regressions per unit.
+// Heap and GC metrics are subject to GC pacing and sampling
+// and therefore have higher thresholds than time and allocations.
+var DefaultThresholds = map[string]float64{
+	UnitNsPerOp:        0.05,
+	UnitBytesPerOp:     0.05,
+	UnitAllocsPerOp:    0,
+	UnitPeakHeapBytes:  0.10,
+	UnitGCPerOp:        0.10,
+	UnitGCPauseNsPerOp: 0.25,
+}
+
+// HigherIsBetter returns true for throughput units such as MB/s.
+func HigherIsBetter(unit string) bool { return strings.HasSuffix(unit, "/s") }
+
+// Regression is a metric of a case that got worse by more than
+// the threshold of its unit.
+type Regression struct {
+	Key
+	Unit string
+	Base float64
+	New  float64
+}
+
+// Change returns the relative change from Base to New,
+// positive if the metric got worse. The change of a metric that was zero
+// is +Inf if it got worse and 0 otherwise.
+func (r Regression) Change() float64 {
+	if r.Base == 0 {
+		if r.New > 0 && !HigherIsBetter(r.Unit) {
+			return math.Inf(1)
+		}
+		return 0
+	}
+	c := (r.New - r.Base) / r.Base
+	if HigherIsBetter(r.Unit) {
+		return -c
+	}
+	return c
+}
+
+// Regressions returns the regressions of all cases present in both sets
+// for every unit with a threshold in order of first appearance in base.
+// Metrics that were zero have no relative change. B/op and allocs/op
+// are reported as whole numbers and regress if they're no longer zero.
+// All other metrics that were zero are skipped since they're usually
+// rounding noise, such as gc/op of a case that rarely collects garbage.
+func Regressions(base, new *Set, thresholds map[string]float64) []Regression {
+	next := map[Key]Case{}
+	for _, c := range new.Cases() {
+		next[c.Key] = c
+	}
+	units := make([]string, 0, len(thresholds))
+	for u := range thresholds {
+		units = append(units, u)
+	}
+	sort.Slice(units, func(i, j int) bool { return unitIndex(units[i]) < unitIndex(units[j]) })
+
+	var l []Regression
+	for _, c := range base.Cases() {
+		n, ok := next[c.Key]
+		if !ok {
+			continue
+		}
+		for _, u := range units {
+			b, okBase := c.Mean(u)
+			v, okNew := n.Mean(u)
+			if !okBase || !okNew {
+				continue
+			}
+			if b == 0 && u != UnitBytesPerOp && u != UnitAllocsPerOp {
+				continue
+			}
+			r := Regression{Key: c.Key, Unit: u, Base: b, New: v}
+			if r.Change() > thresholds[u] {
+				l = append(l, r)
+			}
+		}
+	}
+	return l
+}
+
+// unitIndex returns the index of u in Units or len(Units) if unknown.
+func unitIndex(u string) int {
+	for i, x := range Units {
+		if x == u {
+			return i
+		}
+	}
+	return len(Units)
+}
+
+// ParseThresholds parses comma-separated unit=threshold pairs
+// such as "ns/op=0.05,peak-heap-B=0.2" overriding DefaultThresholds.
+func ParseThresholds(s string) (map[string]float64, error) {
+	t := make(map[string]float64, len(DefaultThresholds))
+	for u, v := range DefaultThresholds {
+		t[u] = v
+	}
+	if s == "" {
+		return t, nil
+	}
+	for _, p := range strings.Split(s, ",") {
+		u, v, ok := strings.Cut(p, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid threshold %q, expected unit=value", p)
+		}
+		f, err := strconv.ParseFloat(v, 64)
+		if err != nil || f < 0 {
+			return nil, fmt.Errorf("invalid threshold %q", p)
+		}
+		t[u] = f
+	}
+	return t, nil
+}
+
+// WriteRegressions writes a markdown table of the regressions to w.
+func WriteRegressions(w io.Writer, r []Regression) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "### Regressions\n\n")
+	if len(r) < 1 {
+		b.WriteString("None.\n\n")
+	} else {
+		b.WriteString("|suite|input|library|unit|base|new|change|\n|-|-|-|-|-:|-:|-:|\n")
+		for _, x := range r {
+			fmt.Fprintf(&b, "|%s|%s|%s|%s|%s|%s|%+.1f%%|\n",
+				x.Suite, x.Input, x.Library, x.Unit,
+				FormatValue(x.Unit, x.Base), FormatValue(x.Unit, x.New), x.Change()*100)
+		}
+		b.WriteByte('\n')
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
@@ -0,0 +1,56 @@
+package results_test
+
+import (
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/romshark/jscan-benchmark/results"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegressions(t *testing.T) {
+	base, err := results.Parse(strings.NewReader(`pkg: x/validation
+BenchmarkValid/tiny/jscan 1 100 ns/op 10.00 MB/s 0 B/op 0 allocs/op 1000 peak-heap-B 0 gc/op
+BenchmarkValid/tiny/other 1 100 ns/op 10.00 MB/s 8 B/op 1 allocs/op 1000 peak-heap-B 0 gc/op
+BenchmarkValid/tiny/alloc 1 100 ns/op 10.00 MB/s 0 B/op 0 allocs/op 1000 peak-heap-B 1 gc/op
+`))
+	require.NoError(t, err)
+	next, err := results.Parse(strings.NewReader(`pkg: x/validation
+BenchmarkValid/tiny/jscan 1 104 ns/op 5.00 MB/s 0 B/op 0 allocs/op 1200 peak-heap-B 0 gc/op
+BenchmarkValid/tiny/other 1 200 ns/op 10.00 MB/s 8 B/op 2 allocs/op 1000 peak-heap-B 0.5 gc/op
+BenchmarkValid/tiny/alloc 1 100 ns/op 10.00 MB/s 16 B/op 1 allocs/op 1000 peak-heap-B 2 gc/op
+`))
+	require.NoError(t, err)
+
+	th, err := results.ParseThresholds("MB/s=0.1")
+	require.NoError(t, err)
+
+	r := results.Regressions(base, next, th)
+	type regression struct {
+		Library, Unit string
+	}
+	var got []regression
+	for _, x := range r {
+		got = append(got, regression{x.Library, x.Unit})
+	}
+	require.Equal(t, []regression{
+		{"jscan", results.UnitMBPerSec},
+		{"jscan", results.UnitPeakHeapBytes},
+		{"other", results.UnitNsPerOp},
+		{"other", results.UnitAllocsPerOp},
+		{"alloc", results.UnitBytesPerOp},
+		{"alloc", results.UnitAllocsPerOp},
+		{"alloc", results.UnitGCPerOp},
+	}, got, "gc/op of other was zero and must be skipped")
+	require.InDelta(t, 0.5, r[0].Change(), 1e-9)
+	require.True(t, math.IsInf(r[4].Change(), 1))
+
+	var b strings.Builder
+	require.NoError(t, results.WriteRegressions(&b, r))
+	require.Contains(t, b.String(), "|validation|tiny|other|ns/op|100.00|200.00|+100.0%|")
+
+	_, err = results.ParseThresholds("ns/op")
+	require.Error(t, err)
+}
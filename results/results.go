@@ -0,0 +1,350 @@
+// Package results parses, aggregates and renders benchmark results
+package results
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Standard and custom benchmark metric units,
+// the custom ones are reported by the test package.
+const (
+	UnitNsPerOp         = "ns/op"
+	UnitMBPerSec        = "MB/s"
+	UnitValuesPerSec    = "values/s"
+	UnitDocumentsPerSec = "docs/s"
+	UnitBytesPerOp      = "B/op"
+	UnitAllocsPerOp     = "allocs/op"
+	UnitPeakHeapBytes   = "peak-heap-B"
+	UnitGCPerOp         = "gc/op"
+	UnitGCPauseNsPerOp  = "gc-pause-ns/op"
+	UnitP50Ns           = "p50-ns"
+	UnitP90Ns           = "p90-ns"
+	UnitP99Ns           = "p99-ns"
+	UnitP999Ns          = "p99.9-ns"
+	UnitMaxNs           = "max-ns"
+)
+
+// Units lists the known metric units in the order they're rendered.
+var Units = []string{
+	UnitNsPerOp,
+	UnitMBPerSec,
+	UnitValuesPerSec,
+	UnitDocumentsPerSec,
+	UnitBytesPerOp,
+	UnitAllocsPerOp,
+	UnitPeakHeapBytes,
+	UnitGCPerOp,
+	UnitGCPauseNsPerOp,
+	UnitP50Ns,
+	UnitP90Ns,
+	UnitP99Ns,
+	UnitP999Ns,
+	UnitMaxNs,
+}
+
+// Key identifies a benchmark case.
+type Key struct {
+	Suite   string `json:"suite"`
+	Input   string `json:"input"`
+	Library string `json:"library"`
+}
+
+func (k Key) String() string { return k.Suite + "/" + k.Input + "/" + k.Library }
+
+// Result is a single benchmark result line.
+type Result struct {
+	Key
+	Pkg     string             `json:"pkg,omitempty"` // Import path
+	Name    string             `json:"name"`          // Without GOMAXPROCS suffix
+	Procs   int                `json:"procs,omitempty"`
+	N       int                `json:"n"`
+	Metrics map[string]float64 `json:"metrics"`
+}
+
+// Set is a set of benchmark results parsed from the output of go test.
+type Set struct {
+	Goos    string   `json:"goos,omitempty"`
+	Goarch  string   `json:"goarch,omitempty"`
+	CPU     string   `json:"cpu,omitempty"`
+	Results []Result `json:"results"`
+
+	// Versions maps the benchmarked modules to their versions as
+	// selected by the go command when building the test binaries
+	// (see runner.Suite.Versions) or, for plain go test output,
+	// as printed by the test binaries (see test.Versions).
+	Versions map[string]string `json:"versions,omitempty"`
+
+	// Failures are the benchmark cases that failed, for example because
+	// a library returned a wrong result for an input.
+	Failures []Failure `json:"failures,omitempty"`
+}
+
+// Failure is a failed benchmark case.
+type Failure struct {
+	Key
+	Message string `json:"message,omitempty"`
+}
+
+// Parse parses the output of `go test -bench`.
+// Failed benchmark cases are collected with the first line logged
+// after their failure. Lines that aren't benchmark results,
+// failures or configuration are ignored.
+func Parse(r io.Reader) (*Set, error) {
+	s := new(Set)
+	var pkg string
+	var failure *Failure // Awaiting its message
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 64*1024), 1024*1024)
+	for line := 1; sc.Scan(); line++ {
+		l := sc.Text()
+		if t := strings.TrimSpace(l); strings.HasPrefix(t, "--- FAIL: Benchmark") {
+			failure = s.addFailure(pkg, t)
+			continue
+		} else if failure != nil && strings.HasPrefix(l, " ") && t != "" {
+			failure.Message, failure = t, nil
+			continue
+		}
+		failure = nil
+		switch {
+		case strings.HasPrefix(l, "goos: "):
+			s.Goos = strings.TrimPrefix(l, "goos: ")
+		case strings.HasPrefix(l, "goarch: "):
+			s.Goarch = strings.TrimPrefix(l, "goarch: ")
+		case strings.HasPrefix(l, "cpu: "):
+			s.CPU = strings.TrimPrefix(l, "cpu: ")
+		case strings.HasPrefix(l, "pkg: "):
+			pkg = strings.TrimPrefix(l, "pkg: ")
+		case strings.HasPrefix(l, "version/"):
+			if k, v, ok := strings.Cut(strings.TrimPrefix(l, "version/"), ": "); ok {
+				s.AddVersions(map[string]string{k: v})
+			}
+		case strings.HasPrefix(l, "Benchmark"):
+			r, ok, err := parseResult(pkg, l)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", line, err)
+			}
+			if ok {
+				s.Results = append(s.Results, r)
+			}
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return nil, fmt.Errorf("reading input: %w", err)
+	}
+	return s, nil
+}
+
+// addFailure adds the failure of a "--- FAIL: " line unless it's
+// a failure of a parent benchmark or was already added
+// and returns it, or returns nil.
+func (s *Set) addFailure(pkg, l string) *Failure {
+	name := strings.TrimPrefix(l, "--- FAIL: ")
+	if i := strings.IndexByte(name, ' '); i != -1 {
+		name = name[:i] // Duration
+	}
+	k := ParseName(pkg, strings.TrimPrefix(name, "Benchmark"))
+	if k.Library == "" {
+		return nil
+	}
+	for _, f := range s.Failures {
+		if f.Key == k {
+			return nil
+		}
+	}
+	s.Failures = append(s.Failures, Failure{Key: k})
+	return &s.Failures[len(s.Failures)-1]
+}
+
+func parseResult(pkg, l string) (r Result, ok bool, err error) {
+	f := strings.Fields(l)
+	if len(f) < 4 || len(f)%2 != 0 {
+		// Not a result line, probably logs or a benchmark name only
+		return r, false, nil
+	}
+	if r.N, err = strconv.Atoi(f[1]); err != nil {
+		return r, false, nil
+	}
+
+	r.Pkg, r.Name = pkg, f[0]
+	if i := strings.LastIndexByte(r.Name, '-'); i != -1 {
+		if p, err := strconv.Atoi(r.Name[i+1:]); err == nil {
+			r.Procs, r.Name = p, r.Name[:i]
+		}
+	}
+	r.Key = ParseName(pkg, strings.TrimPrefix(r.Name, "Benchmark"))
+
+	r.Metrics = make(map[string]float64, (len(f)-2)/2)
+	for i := 2; i < len(f); i += 2 {
+		v, err := strconv.ParseFloat(f[i], 64)
+		if err != nil {
+			return r, false, fmt.Errorf("parsing metric %q: %w", f[i+1], err)
+		}
+		r.Metrics[f[i+1]] = v
+	}
+	return r, true, nil
+}
+
+// ParseName returns the key of the benchmark name
+// (without the "Benchmark" prefix and GOMAXPROCS suffix) in package pkg.
+// Names are expected to follow the pattern "Function/input/library"
+// where deeper levels are considered part of the library name.
+// Underscore padding is removed.
+func ParseName(pkg, name string) Key {
+	s := strings.Split(name, "/")
+	for i := range s {
+		s[i] = strings.TrimRight(s[i], "_")
+	}
+	k := Key{Suite: path.Base(pkg)}
+	if pkg == "" {
+		k.Suite = s[0]
+	}
+	if len(s) > 1 {
+		k.Input = s[1]
+	}
+	if len(s) > 2 {
+		k.Library = strings.Join(s[2:], "/")
+	}
+	return k
+}
+
+// Case is a benchmark case with all of its samples.
+type Case struct {
+	Key
+	Samples []Result
+}
+
+// Values returns all sample values of unit.
+func (c Case) Values(unit string) []float64 {
+	v := make([]float64, 0, len(c.Samples))
+	for _, s := range c.Samples {
+		if x, ok := s.Metrics[unit]; ok {
+			v = append(v, x)
+		}
+	}
+	return v
+}
+
+// Mean returns the arithmetic mean of unit over all samples.
+// Returns false if no sample reported unit.
+func (c Case) Mean(unit string) (float64, bool) {
+	v := c.Values(unit)
+	if len(v) < 1 {
+		return 0, false
+	}
+	return mean(v), true
+}
+
+// AddVersions adds the module versions v to the set.
+func (s *Set) AddVersions(v map[string]string) {
+	if len(v) > 0 && s.Versions == nil {
+		s.Versions = make(map[string]string, len(v))
+	}
+	for k, x := range v {
+		s.Versions[k] = x
+	}
+}
+
+// AddFailures adds the failures f to the set ignoring cases
+// that already failed.
+func (s *Set) AddFailures(f []Failure) {
+	for _, f := range f {
+		known := false
+		for _, x := range s.Failures {
+			if x.Key == f.Key {
+				known = true
+				break
+			}
+		}
+		if !known {
+			s.Failures = append(s.Failures, f)
+		}
+	}
+}
+
+// Cases groups results by key in order of first appearance.
+func (s *Set) Cases() []Case {
+	index := map[Key]int{}
+	var c []Case
+	for _, r := range s.Results {
+		i, ok := index[r.Key]
+		if !ok {
+			i = len(c)
+			index[r.Key] = i
+			c = append(c, Case{Key: r.Key})
+		}
+		c[i].Samples = append(c[i].Samples, r)
+	}
+	return c
+}
+
+// Suites returns the names of all suites in order of first appearance.
+func (s *Set) Suites() []string {
+	var l []string
+	seen := map[string]bool{}
+	for _, r := range s.Results {
+		if !seen[r.Suite] {
+			seen[r.Suite] = true
+			l = append(l, r.Suite)
+		}
+	}
+	return l
+}
+
+// UnitsOf returns the units reported by cases in the order of Units
+// followed by unknown units in alphabetical order.
+func UnitsOf(c []Case) []string {
+	present := map[string]bool{}
+	for _, c := range c {
+		for _, s := range c.Samples {
+			for u := range s.Metrics {
+				present[u] = true
+			}
+		}
+	}
+	l := make([]string, 0, len(present))
+	for _, u := range Units {
+		if present[u] {
+			l = append(l, u)
+			delete(present, u)
+		}
+	}
+	other := make([]string, 0, len(present))
+	for u := range present {
+		other = append(other, u)
+	}
+	sort.Strings(other)
+	return append(l, other...)
+}
+
+// FormatValue formats metric value v of unit for humans.
+func FormatValue(unit string, v float64) string {
+	switch unit {
+	case UnitBytesPerOp, UnitAllocsPerOp,
+		UnitP50Ns, UnitP90Ns, UnitP99Ns, UnitP999Ns, UnitMaxNs:
+		return strconv.FormatFloat(v, 'f', 0, 64)
+	case UnitValuesPerSec, UnitDocumentsPerSec, UnitPeakHeapBytes:
+		return formatSI(v)
+	case UnitGCPerOp:
+		return strconv.FormatFloat(v, 'g', 3, 64)
+	}
+	return strconv.FormatFloat(v, 'f', 2, 64)
+}
+
+func formatSI(v float64) string {
+	for _, p := range []struct {
+		f float64
+		s string
+	}{{1e12, "T"}, {1e9, "G"}, {1e6, "M"}, {1e3, "k"}} {
+		if math.Abs(v) >= p.f {
+			return strconv.FormatFloat(v/p.f, 'f', 2, 64) + p.s
+		}
+	}
+	return strconv.FormatFloat(v, 'f', 2, 64)
+}
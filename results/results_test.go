@@ -0,0 +1,97 @@
+package results_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/romshark/jscan-benchmark/results"
+
+	"github.com/stretchr/testify/require"
+)
+
+const output = `goos: darwin
+goarch: arm64
+pkg: github.com/romshark/jscan-benchmark/calcstats
+version/encoding/json: go1.21.5
+version/github.com/romshark/jscan/v2: v2.0.2
+BenchmarkCalcStats/tiny_8b_______________/jscan___________-10         	41999097	        28.57 ns/op	 280.00 MB/s	 70000000 values/s	       0 B/op	       0 allocs/op
+BenchmarkCalcStats/tiny_8b_______________/jscan___________-10         	41999097	        30.57 ns/op	 261.69 MB/s	 65420000 values/s	       0 B/op	       0 allocs/op
+BenchmarkCalcStats/tiny_8b_______________/jsoniter________-10         	26395453	        44.93 ns/op	      16 B/op	       1 allocs/op
+PASS
+ok  	github.com/romshark/jscan-benchmark/calcstats	1.2s
+pkg: github.com/romshark/jscan-benchmark/array2d_int
+BenchmarkDecode2DArray/large_1kb/romshark_jscan 	 1000	 1510 ns/op
+`
+
+func TestParse(t *testing.T) {
+	s, err := results.Parse(strings.NewReader(output))
+	require.NoError(t, err)
+	require.Equal(t, "darwin", s.Goos)
+	require.Equal(t, "arm64", s.Goarch)
+	require.Equal(t, map[string]string{
+		"encoding/json":                "go1.21.5",
+		"github.com/romshark/jscan/v2": "v2.0.2",
+	}, s.Versions)
+	require.Len(t, s.Results, 4)
+	require.Equal(t, results.Result{
+		Key: results.Key{
+			Suite:   "calcstats",
+			Input:   "tiny_8b",
+			Library: "jscan",
+		},
+		Pkg:   "github.com/romshark/jscan-benchmark/calcstats",
+		Name:  "BenchmarkCalcStats/tiny_8b_______________/jscan___________",
+		Procs: 10,
+		N:     41999097,
+		Metrics: map[string]float64{
+			results.UnitNsPerOp:      28.57,
+			results.UnitMBPerSec:     280,
+			results.UnitValuesPerSec: 70000000,
+			results.UnitBytesPerOp:   0,
+			results.UnitAllocsPerOp:  0,
+		},
+	}, s.Results[0])
+	require.Equal(t, results.Key{
+		Suite:   "array2d_int",
+		Input:   "large_1kb",
+		Library: "romshark_jscan",
+	}, s.Results[3].Key)
+	require.Equal(t, []string{"calcstats", "array2d_int"}, s.Suites())
+
+	c := s.Cases()
+	require.Len(t, c, 3)
+	require.Len(t, c[0].Samples, 2)
+	m, ok := c[0].Mean(results.UnitNsPerOp)
+	require.True(t, ok)
+	require.InDelta(t, 29.57, m, 1e-9)
+	_, ok = c[1].Mean(results.UnitMBPerSec)
+	require.False(t, ok)
+}
+
+func TestParseFailures(t *testing.T) {
+	s, err := results.Parse(strings.NewReader(`pkg: github.com/romshark/jscan-benchmark/validation
+BenchmarkValid/unwind_stack__________/jscan___________-8 	 1000	 1510 ns/op
+--- FAIL: BenchmarkValid/unwind_stack__________/ohler55_ojg_oj__
+    validation_test.go:201: wrong verdict: invalid reported as valid
+--- FAIL: BenchmarkValid/unwind_stack__________
+--- FAIL: BenchmarkValid
+--- FAIL: BenchmarkValid/unwind_stack__________/ohler55_ojg_oj__
+    validation_test.go:201: wrong verdict: invalid reported as valid
+FAIL
+`))
+	require.NoError(t, err)
+	require.Len(t, s.Results, 1)
+	require.Equal(t, []results.Failure{{
+		Key: results.Key{
+			Suite:   "validation",
+			Input:   "unwind_stack",
+			Library: "ohler55_ojg_oj",
+		},
+		Message: "validation_test.go:201: wrong verdict: invalid reported as valid",
+	}}, s.Failures)
+
+	var b strings.Builder
+	require.NoError(t, results.WriteMarkdown(&b, s, results.Diagnostics{}))
+	require.Contains(t, b.String(), "|validation|unwind_stack|ohler55_ojg_oj|"+
+		"validation_test.go:201: wrong verdict: invalid reported as valid|")
+}
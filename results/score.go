@@ -0,0 +1,200 @@
+package results
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strings"
+)
+
+// DefaultBaseline is the library other libraries are compared against.
+const DefaultBaseline = "jscan"
+
+// Scoring configures the geometric-mean scoring of libraries
+// relative to a baseline library.
+type Scoring struct {
+	// Baseline is the name of the baseline library.
+	// A library matches the baseline if its name equals Baseline
+	// or ends with "_" followed by Baseline (e.g. "romshark_jscan").
+	Baseline string
+
+	// Weights maps input categories to weights.
+	// The weight of an input is the product of the weights
+	// of all its categories, categories not in Weights weigh 1.
+	Weights map[string]float64
+
+	// Categories returns the categories of the input of c.
+	// If nil, inputs aren't categorized and all weigh 1.
+	Categories func(c Case) []string
+}
+
+// Score is the weighted geometric-mean speedup of a library
+// relative to the baseline. A speedup of 2 means twice as fast
+// as the baseline, 0.5 means twice as slow.
+type Score struct {
+	Library string
+	Overall float64
+	Suites  map[string]float64
+	Inputs  int
+}
+
+// IsBaseline returns true if library matches the baseline.
+func (s Scoring) IsBaseline(library string) bool {
+	return library == s.Baseline || strings.HasSuffix(library, "_"+s.Baseline)
+}
+
+// Weight returns the weight of the input of c.
+func (s Scoring) Weight(c Case) float64 {
+	if s.Categories == nil {
+		return 1
+	}
+	w := 1.0
+	for _, x := range s.Categories(c) {
+		if cw, ok := s.Weights[x]; ok {
+			w *= cw
+		}
+	}
+	return w
+}
+
+// Scores computes the per-suite and overall scores of all libraries in set
+// sorted by overall score in descending order.
+// Inputs for which the baseline has no results are ignored.
+func (s Scoring) Scores(set *Set) []Score {
+	type acc struct{ sum, weight float64 }
+	type libAcc struct {
+		overall acc
+		suites  map[string]*acc
+		inputs  int
+	}
+
+	cases := set.Cases()
+	base := map[[2]string]float64{}
+	for _, c := range cases {
+		if s.IsBaseline(c.Library) {
+			if t, ok := c.Mean(UnitNsPerOp); ok && t > 0 {
+				base[[2]string{c.Suite, c.Input}] = t
+			}
+		}
+	}
+
+	var order []string
+	libs := map[string]*libAcc{}
+	for _, c := range cases {
+		tb, ok := base[[2]string{c.Suite, c.Input}]
+		if !ok {
+			continue
+		}
+		t, ok := c.Mean(UnitNsPerOp)
+		if !ok || t <= 0 {
+			continue
+		}
+		name := c.Library
+		if s.IsBaseline(name) {
+			name = s.Baseline
+		}
+		l := libs[name]
+		if l == nil {
+			l = &libAcc{suites: map[string]*acc{}}
+			libs[name] = l
+			order = append(order, name)
+		}
+		w := s.Weight(c)
+		if w <= 0 {
+			continue
+		}
+		x := w * math.Log(tb/t)
+		l.overall.sum += x
+		l.overall.weight += w
+		a := l.suites[c.Suite]
+		if a == nil {
+			a = new(acc)
+			l.suites[c.Suite] = a
+		}
+		a.sum += x
+		a.weight += w
+		l.inputs++
+	}
+
+	scores := make([]Score, 0, len(order))
+	for _, name := range order {
+		l := libs[name]
+		if l.overall.weight <= 0 {
+			continue
+		}
+		sc := Score{
+			Library: name,
+			Overall: math.Exp(l.overall.sum / l.overall.weight),
+			Suites:  make(map[string]float64, len(l.suites)),
+			Inputs:  l.inputs,
+		}
+		for suite, a := range l.suites {
+			sc.Suites[suite] = math.Exp(a.sum / a.weight)
+		}
+		scores = append(scores, sc)
+	}
+	sort.SliceStable(scores, func(i, j int) bool {
+		return scores[i].Overall > scores[j].Overall
+	})
+	return scores
+}
+
+// WriteLeaderboard writes the scores as a markdown table to w.
+func WriteLeaderboard(w io.Writer, s Scoring, suites []string, scores []Score) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "### Leaderboard\n\nSpeedup relative to %s (geometric mean", s.Baseline)
+	if len(s.Weights) > 0 {
+		cat := make([]string, 0, len(s.Weights))
+		for c := range s.Weights {
+			cat = append(cat, c)
+		}
+		sort.Strings(cat)
+		b.WriteString(", weights: ")
+		for i, c := range cat {
+			if i > 0 {
+				b.WriteString(", ")
+			}
+			fmt.Fprintf(&b, "%s=%g", c, s.Weights[c])
+		}
+	}
+	b.WriteString(")\n\n|rank|library|overall|")
+	for _, suite := range suites {
+		fmt.Fprintf(&b, "%s|", suite)
+	}
+	b.WriteString("inputs|\n|-:|-|-:|")
+	for range suites {
+		b.WriteString("-:|")
+	}
+	b.WriteString("-:|\n")
+	for i, sc := range scores {
+		fmt.Fprintf(&b, "|%d|%s|%s|", i+1, sc.Library, FormatSpeedup(sc.Overall))
+		for _, suite := range suites {
+			if v, ok := sc.Suites[suite]; ok {
+				b.WriteString(FormatSpeedup(v))
+			}
+			b.WriteByte('|')
+		}
+		fmt.Fprintf(&b, "%d|\n", sc.Inputs)
+	}
+	b.WriteByte('\n')
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// FormatSpeedup formats a speedup factor for humans.
+func FormatSpeedup(v float64) string { return fmt.Sprintf("%.2fx", v) }
+
+// BytesPerOp returns the number of bytes processed per operation
+// derived from the MB/s and ns/op metrics.
+func (c Case) BytesPerOp() (float64, bool) {
+	mbs, ok := c.Mean(UnitMBPerSec)
+	if !ok {
+		return 0, false
+	}
+	ns, ok := c.Mean(UnitNsPerOp)
+	if !ok {
+		return 0, false
+	}
+	return math.Round(mbs * ns / 1e3), true
+}
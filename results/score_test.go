@@ -0,0 +1,43 @@
+package results_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/romshark/jscan-benchmark/results"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestScores(t *testing.T) {
+	s, err := results.Parse(strings.NewReader(`pkg: x/validation
+BenchmarkValid/tiny/jscan 1 10 ns/op
+BenchmarkValid/tiny/other 1 20 ns/op
+BenchmarkValid/large/jscan 1 100 ns/op
+BenchmarkValid/large/other 1 50 ns/op
+BenchmarkValid/orphan/other 1 1 ns/op
+pkg: x/array2d_int
+BenchmarkDecode2DArray/tiny/romshark_jscan 1 10 ns/op
+BenchmarkDecode2DArray/tiny/other 1 40 ns/op
+`))
+	require.NoError(t, err)
+
+	sc := results.Scoring{Baseline: "jscan"}
+	scores := sc.Scores(s)
+	require.Len(t, scores, 2)
+	require.Equal(t, "jscan", scores[0].Library)
+	require.InDelta(t, 1, scores[0].Overall, 1e-9)
+	require.Equal(t, 3, scores[0].Inputs)
+
+	// (1/2 * 2 * 1/4)^(1/3)
+	require.Equal(t, "other", scores[1].Library)
+	require.InDelta(t, 0.62996, scores[1].Overall, 1e-5)
+	require.InDelta(t, 1, scores[1].Suites["validation"], 1e-9)
+	require.InDelta(t, 0.25, scores[1].Suites["array2d_int"], 1e-9)
+
+	sc.Weights = map[string]float64{"large": 2}
+	sc.Categories = func(c results.Case) []string { return []string{c.Input} }
+	scores = sc.Scores(s)
+	// (1/2 * 2^2 * 1/4)^(1/4)
+	require.InDelta(t, 0.84090, scores[1].Overall, 1e-5)
+}
@@ -0,0 +1,42 @@
+package runner
+
+import (
+	"fmt"
+	"os/exec"
+	"runtime"
+
+	"golang.org/x/sys/unix"
+)
+
+// startPinned starts c with its CPU affinity restricted to cpus.
+// The affinity is set on the locked OS thread that forks the process
+// so that the child and all of its threads inherit it from the start.
+func startPinned(c *exec.Cmd, cpus []int) error {
+	if len(cpus) < 1 {
+		return c.Start()
+	}
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+
+	var prev, set unix.CPUSet
+	if err := unix.SchedGetaffinity(0, &prev); err != nil {
+		return fmt.Errorf("getting CPU affinity: %w", err)
+	}
+	for _, cpu := range cpus {
+		set.Set(cpu)
+	}
+	if err := unix.SchedSetaffinity(0, &set); err != nil {
+		return fmt.Errorf("setting CPU affinity to %v: %w", cpus, err)
+	}
+	if err := c.Start(); err != nil {
+		unix.SchedSetaffinity(0, &prev)
+		return err
+	}
+	if err := unix.SchedSetaffinity(0, &prev); err != nil {
+		// The child started but isn't waited for by the caller.
+		c.Process.Kill()
+		c.Wait()
+		return fmt.Errorf("restoring CPU affinity: %w", err)
+	}
+	return nil
+}
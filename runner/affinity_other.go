@@ -0,0 +1,16 @@
+//go:build !linux
+
+package runner
+
+import (
+	"errors"
+	"os/exec"
+)
+
+// startPinned starts c, pinning is only supported on Linux.
+func startPinned(c *exec.Cmd, cpus []int) error {
+	if len(cpus) > 0 {
+		return errors.New("CPU pinning is only supported on linux")
+	}
+	return c.Start()
+}
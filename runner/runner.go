@@ -0,0 +1,278 @@
+// Package runner compiles the benchmark suites and runs individual
+// benchmark cases in isolated processes
+package runner
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/romshark/jscan-benchmark/results"
+	"github.com/romshark/jscan-benchmark/test"
+)
+
+// Suite is a package containing benchmarks.
+type Suite struct {
+	Pkg string // Import path
+	Dir string // Package directory
+
+	// Binary is the path to the compiled test binary, empty until compiled.
+	Binary string
+
+	// Versions maps the benchmarked modules linked into the test binary
+	// to their versions, nil until compiled. Test binaries built before
+	// Go 1.24 can't report them reliably themselves, see test.Versions.
+	Versions map[string]string
+
+	// CPUs is the set of CPUs test binaries are pinned to,
+	// all CPUs if empty. Pinning is only supported on Linux.
+	CPUs []int
+}
+
+// Name returns the name of the suite as used in results.
+func (s *Suite) Name() string { return filepath.Base(s.Pkg) }
+
+// Suites returns all packages matching patterns that contain tests.
+func Suites(ctx context.Context, patterns ...string) ([]*Suite, error) {
+	args := append([]string{
+		"list", "-f",
+		"{{if or .TestGoFiles .XTestGoFiles}}{{.ImportPath}}\t{{.Dir}}{{end}}",
+	}, patterns...)
+	out, err := output(exec.CommandContext(ctx, "go", args...))
+	if err != nil {
+		return nil, fmt.Errorf("listing packages: %w", err)
+	}
+	var l []*Suite
+	for _, line := range strings.Split(string(out), "\n") {
+		pkg, dir, ok := strings.Cut(line, "\t")
+		if !ok {
+			continue
+		}
+		l = append(l, &Suite{Pkg: pkg, Dir: dir})
+	}
+	return l, nil
+}
+
+// Compile compiles the test binary of the suite into dir
+// passing additional build flags to go test.
+func (s *Suite) Compile(ctx context.Context, dir string, buildFlags ...string) error {
+	bin, err := filepath.Abs(filepath.Join(dir, s.Name()+".test"))
+	if err != nil {
+		return err
+	}
+	args := append([]string{"test", "-c", "-o", bin}, buildFlags...)
+	args = append(args, s.Pkg)
+	if _, err := output(exec.CommandContext(ctx, "go", args...)); err != nil {
+		return fmt.Errorf("compiling %s: %w", s.Pkg, err)
+	}
+	v, err := s.ListVersions(ctx, buildFlags...)
+	if err != nil {
+		return err
+	}
+	s.Binary, s.Versions = bin, v
+	return nil
+}
+
+// ListVersions returns the versions of test.Modules linked into the test
+// binary of the suite built with buildFlags as selected by the go command.
+// Modules replaced by local directories are reported as "(devel)".
+func (s *Suite) ListVersions(ctx context.Context, buildFlags ...string) (map[string]string, error) {
+	args := append([]string{
+		"list", "-deps", "-test", "-f",
+		`{{with .Module}}{{.Path}} {{.Version}}` +
+			`{{with .Replace}} => {{or .Version "(devel)"}}{{end}}{{end}}`,
+	}, buildFlags...)
+	args = append(args, s.Pkg)
+	out, err := output(exec.CommandContext(ctx, "go", args...))
+	if err != nil {
+		return nil, fmt.Errorf("listing modules of %s: %w", s.Pkg, err)
+	}
+	deps := map[string]string{}
+	for _, line := range strings.Split(string(out), "\n") {
+		f := strings.Fields(line)
+		switch {
+		case len(f) == 4 && f[2] == "=>":
+			deps[f[0]] = f[3]
+		case len(f) == 2:
+			deps[f[0]] = f[1]
+		}
+	}
+	v := map[string]string{}
+	for _, m := range test.Modules {
+		if x, ok := deps[m]; ok {
+			v[m] = x
+		}
+	}
+	return v, nil
+}
+
+// Command returns a command executing the test binary in the package
+// directory so that relative testdata paths resolve.
+// The suite must be compiled.
+func (s *Suite) Command(ctx context.Context, args ...string) *exec.Cmd {
+	c := exec.CommandContext(ctx, s.Binary, args...)
+	c.Dir = s.Dir
+	return c
+}
+
+// Case is a single leaf benchmark of a suite.
+type Case struct {
+	Suite *Suite
+	Name  string // Full benchmark name
+	results.Key
+}
+
+// Pattern returns the -test.bench pattern matching only c.
+func (c Case) Pattern() string { return Pattern(c.Name) }
+
+// Run runs only c passing additional test flags
+// and returns the output of the test binary.
+func (c Case) Run(ctx context.Context, flags ...string) ([]byte, error) {
+	return c.RunEnv(ctx, nil, flags...)
+}
+
+// RunEnv is like Run but adds env to the environment of the test binary.
+func (c Case) RunEnv(ctx context.Context, env []string, flags ...string) ([]byte, error) {
+	args := append([]string{"-test.run", "^$", "-test.bench", c.Pattern()}, flags...)
+	cmd := c.Suite.Command(ctx, args...)
+	if len(env) > 0 {
+		cmd.Env = append(os.Environ(), env...)
+	}
+	return c.Suite.output(cmd)
+}
+
+// Results is like RunEnv but parses the output of the test binary.
+// A failed case, for example because a library returned a wrong result
+// for the input, isn't an error but reported in the failures of the set.
+func (c Case) Results(ctx context.Context, env []string, flags ...string) (*results.Set, error) {
+	out, err := c.RunEnv(ctx, env, flags...)
+	set, errParse := results.Parse(bytes.NewReader(out))
+	if err != nil && (errParse != nil || len(set.Failures) < 1) {
+		return nil, fmt.Errorf("running %s: %w", c.Key, err)
+	} else if errParse != nil {
+		return nil, fmt.Errorf("parsing results of %s: %w", c.Key, errParse)
+	}
+	// The output lacks the package if no benchmark completed.
+	for i := range set.Failures {
+		set.Failures[i].Suite = c.Suite.Name()
+	}
+	return set, nil
+}
+
+// Pattern returns a -test.bench pattern matching only the benchmark
+// with the given full name.
+func Pattern(name string) string {
+	s := strings.Split(name, "/")
+	for i := range s {
+		s[i] = "^" + regexp.QuoteMeta(s[i]) + "$"
+	}
+	return strings.Join(s, "/")
+}
+
+// Cases enumerates all leaf benchmarks of the suite.
+// Benchmarks are registered in list mode without running them,
+// see test.EnvList.
+func (s *Suite) Cases(ctx context.Context) ([]Case, error) {
+	c := s.Command(ctx,
+		"-test.run", "^$",
+		"-test.bench", ".",
+		"-test.benchtime", "1x",
+	)
+	c.Env = append(os.Environ(), test.EnvList+"=1")
+	out, err := s.output(c)
+	if err != nil {
+		return nil, fmt.Errorf("enumerating cases of %s: %w", s.Pkg, err)
+	}
+	set, err := results.Parse(bytes.NewReader(out))
+	if err != nil {
+		return nil, err
+	}
+	var l []Case
+	seen := map[string]bool{}
+	for _, r := range set.Results {
+		if seen[r.Name] {
+			continue
+		}
+		seen[r.Name] = true
+		r.Key.Suite = s.Name()
+		l = append(l, Case{Suite: s, Name: r.Name, Key: r.Key})
+	}
+	return l, nil
+}
+
+// output runs test binary command c pinned to the CPUs of the suite.
+func (s *Suite) output(c *exec.Cmd) ([]byte, error) {
+	var stdout, stderr bytes.Buffer
+	c.Stdout, c.Stderr = &stdout, &stderr
+	if err := startPinned(c, s.CPUs); err != nil {
+		return nil, err
+	}
+	return wait(c, &stdout, &stderr)
+}
+
+// output runs c and returns its standard output.
+// Standard error is included in the error if c fails.
+func output(c *exec.Cmd) ([]byte, error) {
+	var stdout, stderr bytes.Buffer
+	c.Stdout, c.Stderr = &stdout, &stderr
+	if err := c.Start(); err != nil {
+		return nil, err
+	}
+	return wait(c, &stdout, &stderr)
+}
+
+func wait(c *exec.Cmd, stdout, stderr *bytes.Buffer) ([]byte, error) {
+	err := c.Wait()
+	out := stdout.Bytes()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String() + "\n" + tail(out)); msg != "" {
+			return out, fmt.Errorf("%w: %s", err, msg)
+		}
+		return out, err
+	}
+	return out, nil
+}
+
+// tail returns the last lines of the output.
+func tail(b []byte) string {
+	l := strings.Split(strings.TrimSpace(string(b)), "\n")
+	if len(l) > 10 {
+		l = l[len(l)-10:]
+	}
+	return strings.Join(l, "\n")
+}
+
+// maxCPUs is the number of CPUs of the Linux CPU set that can be pinned to.
+const maxCPUs = 1024
+
+// ParseCPUs parses a CPU list such as "0-3,6".
+// CPUs must be less than 1024.
+func ParseCPUs(s string) ([]int, error) {
+	if s == "" {
+		return nil, nil
+	}
+	var l []int
+	for _, p := range strings.Split(s, ",") {
+		from, to, isRange := strings.Cut(strings.TrimSpace(p), "-")
+		a, err := strconv.Atoi(from)
+		if err != nil || a < 0 || a >= maxCPUs {
+			return nil, fmt.Errorf("invalid CPU %q", p)
+		}
+		b := a
+		if isRange {
+			if b, err = strconv.Atoi(to); err != nil || b < a || b >= maxCPUs {
+				return nil, fmt.Errorf("invalid CPU range %q", p)
+			}
+		}
+		for i := a; i <= b; i++ {
+			l = append(l, i)
+		}
+	}
+	return l, nil
+}
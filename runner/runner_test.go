@@ -0,0 +1,63 @@
+package runner_test
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/romshark/jscan-benchmark/runner"
+	"github.com/romshark/jscan-benchmark/test"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPattern(t *testing.T) {
+	p := runner.Pattern("BenchmarkValid/tiny_8b___/jscan___")
+	require.Equal(t, `^BenchmarkValid$/^tiny_8b___$/^jscan___$`, p)
+	require.Equal(t, `^Benchmark\.x$/^a\+$`, runner.Pattern("Benchmark.x/a+"))
+}
+
+func TestParseCPUs(t *testing.T) {
+	for _, td := range []struct {
+		input  string
+		expect []int
+		err    bool
+	}{
+		{input: "", expect: nil},
+		{input: "2", expect: []int{2}},
+		{input: "0-3,6", expect: []int{0, 1, 2, 3, 6}},
+		{input: "1, 3-4", expect: []int{1, 3, 4}},
+		{input: "3-1", err: true},
+		{input: "a", err: true},
+		{input: "-1", err: true},
+		{input: "1023", expect: []int{1023}},
+		{input: "1024", err: true},
+		{input: "1000-1100", err: true},
+	} {
+		t.Run(td.input, func(t *testing.T) {
+			l, err := runner.ParseCPUs(td.input)
+			if td.err {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, td.expect, l)
+		})
+	}
+}
+
+func TestListVersions(t *testing.T) {
+	suites, err := runner.Suites(context.Background(), "../validation")
+	require.NoError(t, err)
+	require.Len(t, suites, 1)
+	v, err := suites[0].ListVersions(context.Background())
+	require.NoError(t, err)
+
+	b, err := os.ReadFile("../go.mod")
+	require.NoError(t, err)
+	required, _ := test.ParseGoMod(b)
+	require.Len(t, v, len(test.Modules))
+	for m, x := range v {
+		require.Equal(t, required[m], x, m)
+	}
+}
@@ -0,0 +1,190 @@
+package test
+
+import (
+	"strings"
+	"testing"
+)
+
+// Input categories used to group inputs in reports and scoring.
+const (
+	CategoryTiny        = "tiny"
+	CategorySmall       = "small"
+	CategoryLarge       = "large"
+	CategoryStringHeavy = "string-heavy"
+	CategoryNumberHeavy = "number-heavy"
+)
+
+// Input is a benchmark input described by the corpus manifest.
+type Input struct {
+	Name   string
+	Source SourceProvider
+
+	// Values is the number of JSON values contained in the input,
+	// 0 if the input is invalid or the number is unknown.
+	Values int
+
+	// Documents is the number of JSON documents contained in the input.
+	// 0 is treated as 1.
+	Documents int
+
+	// Invalid is true if the input isn't valid JSON
+	// and is expected to be rejected.
+	Invalid bool
+
+	Categories []string
+}
+
+// BenchName returns the name of the input padded with underscores
+// to align benchmark output.
+func (i Input) BenchName() string { return Pad(i.Name, 22) }
+
+// Throughput returns the throughput of the input for a single operation
+// over src.
+func (i Input) Throughput(src []byte) Throughput {
+	d := i.Documents
+	if d < 1 {
+		d = 1
+	}
+	return Throughput{Bytes: len(src), Values: i.Values, Documents: d}
+}
+
+// Verify fails tb if the verdict valid returned by a library
+// doesn't match the validity of the input.
+func (i Input) Verify(tb testing.TB, valid bool) {
+	tb.Helper()
+	if valid == !i.Invalid {
+		return
+	}
+	tb.Fatalf("wrong verdict: %s reported as %s", verdict(!i.Invalid), verdict(valid))
+}
+
+func verdict(valid bool) string {
+	if valid {
+		return "valid"
+	}
+	return "invalid"
+}
+
+// HasCategory returns true if the input belongs to category c.
+func (i Input) HasCategory(c string) bool {
+	for _, x := range i.Categories {
+		if x == c {
+			return true
+		}
+	}
+	return false
+}
+
+// Corpus is the manifest of the file-based inputs in testdata.
+var Corpus = []Input{
+	{
+		Name:       "miniscule_1b",
+		Source:     SrcFile("miniscule_1b.json"),
+		Values:     1,
+		Categories: []string{CategoryTiny, CategoryNumberHeavy},
+	},
+	{
+		Name:       "tiny_8b",
+		Source:     SrcFile("tiny_8b.json"),
+		Values:     2,
+		Categories: []string{CategoryTiny},
+	},
+	{
+		Name:       "small_336b",
+		Source:     SrcFile("small_336b.json"),
+		Values:     19,
+		Categories: []string{CategorySmall},
+	},
+	{
+		Name:       "large_26m",
+		Source:     SrcFile("large_26m.json.gz"),
+		Values:     637595,
+		Categories: []string{CategoryLarge, CategoryStringHeavy},
+	},
+	{
+		Name:       "nasa_SxSW_2016_125k",
+		Source:     SrcFile("nasa_SxSW_2016_125k.json.gz"),
+		Values:     8077,
+		Categories: []string{CategoryLarge},
+	},
+	{
+		Name:       "escaped_3k",
+		Source:     SrcFile("escaped_3k.json"),
+		Values:     2,
+		Categories: []string{CategorySmall, CategoryStringHeavy},
+	},
+	{
+		Name:       "array_int_1024_12k",
+		Source:     SrcFile("array_int_1024_12k.json"),
+		Values:     1025,
+		Categories: []string{CategorySmall, CategoryNumberHeavy},
+	},
+	{
+		Name:       "array_dec_1024_10k",
+		Source:     SrcFile("array_dec_1024_10k.json"),
+		Values:     1025,
+		Categories: []string{CategorySmall, CategoryNumberHeavy},
+	},
+	{
+		Name:       "array_nullbool_1024_5k",
+		Source:     SrcFile("array_nullbool_1024_5k.json"),
+		Values:     1025,
+		Categories: []string{CategorySmall},
+	},
+	{
+		Name:       "array_str_1024_639k",
+		Source:     SrcFile("array_str_1024_639k.json"),
+		Values:     1025,
+		Categories: []string{CategoryLarge, CategoryStringHeavy},
+	},
+	{
+		Name:       "array_cjk_1024_113k",
+		Source:     SrcFile("array_cjk_1024_113k.json"),
+		Values:     1025,
+		Categories: []string{CategoryLarge, CategoryStringHeavy},
+	},
+	{
+		Name:       "array_emoji_1024_84k",
+		Source:     SrcFile("array_emoji_1024_84k.json"),
+		Values:     1025,
+		Categories: []string{CategoryLarge, CategoryStringHeavy},
+	},
+}
+
+// Size category thresholds in bytes.
+const (
+	SizeSmall = 64
+	SizeLarge = 64 * 1024
+)
+
+// SizeCategory returns the size category of an input of the given size.
+func SizeCategory(bytes int) string {
+	switch {
+	case bytes < SizeSmall:
+		return CategoryTiny
+	case bytes < SizeLarge:
+		return CategorySmall
+	}
+	return CategoryLarge
+}
+
+// Lookup returns the corpus input with the given name.
+func Lookup(name string) (Input, bool) {
+	for _, i := range Corpus {
+		if i.Name == name {
+			return i, true
+		}
+	}
+	return Input{}, false
+}
+
+// Pad pads s with underscores to length l.
+func Pad(s string, l int) string {
+	if len(s) >= l {
+		return s
+	}
+	return s + strings.Repeat("_", l-len(s))
+}
+
+// Unpad removes the underscore padding added by Pad.
+func Unpad(s string) string { return strings.TrimRight(s, "_") }
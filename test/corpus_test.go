@@ -0,0 +1,21 @@
+package test_test
+
+import (
+	"testing"
+
+	"github.com/romshark/jscan-benchmark/test"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCorpusManifest(t *testing.T) {
+	for _, in := range test.Corpus {
+		t.Run(in.Name, func(t *testing.T) {
+			src, err := in.Source.GetJSON()
+			require.NoError(t, err)
+			require.Equal(t, in.Values, test.CountValues(src))
+			require.True(t, in.HasCategory(test.SizeCategory(len(src))),
+				"expected size category %q", test.SizeCategory(len(src)))
+		})
+	}
+}
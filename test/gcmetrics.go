@@ -0,0 +1,165 @@
+package test
+
+import (
+	"math"
+	"os"
+	"runtime"
+	"runtime/metrics"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/romshark/jscan-benchmark/results"
+)
+
+// HeapSampleInterval is the interval at which the heap in-use is sampled
+// to determine its peak.
+const HeapSampleInterval = time.Millisecond
+
+// EnvPeakHeap is the environment variable that, when set to "1", makes
+// Run sample the heap in-use every HeapSampleInterval and report its peak.
+// Sampling runs concurrently with the benchmark and is disabled by default
+// to not perturb timing.
+const EnvPeakHeap = "JSCANBENCH_PEAK_HEAP"
+
+var samplePeakHeap = os.Getenv(EnvPeakHeap) == "1"
+
+const (
+	metricGCCycles  = "/gc/cycles/total:gc-cycles"
+	metricGCPauses  = "/gc/pauses:seconds"
+	metricHeapInUse = "/memory/classes/heap/objects:bytes"
+)
+
+// gcMetrics measures the heap and GC metrics of a benchmark run
+// from its start or the last call to ResetTimer.
+type gcMetrics struct {
+	cycles uint64
+	pauses *metrics.Float64Histogram
+	base   uint64
+
+	mu   sync.Mutex
+	peak uint64
+	stop chan struct{}
+	done chan struct{}
+}
+
+// running are the metrics of the running benchmark, nil if none is running.
+var running *gcMetrics
+
+// startGCMetrics starts measuring. The testing package collects garbage
+// before every run, so the heap in-use at the start is the baseline
+// the peak is relative to.
+func startGCMetrics() *gcMetrics {
+	m := &gcMetrics{}
+	m.reset()
+	if samplePeakHeap {
+		m.stop, m.done = make(chan struct{}), make(chan struct{})
+		go m.sample()
+	}
+	running = m
+	return m
+}
+
+// reset makes the metrics start at the current state.
+func (m *gcMetrics) reset() {
+	s := readGCMetrics()
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.cycles = s[0].Value.Uint64()
+	m.pauses = s[1].Value.Float64Histogram()
+	m.base = s[2].Value.Uint64()
+	m.peak = m.base
+}
+
+// ResetTimer resets the timer of b like b.ResetTimer and the heap and GC
+// metrics of Run after collecting the garbage of the setup, so that the
+// peak heap is relative to the heap in-use after the setup.
+// Benchmarks run through Run must call it instead of b.ResetTimer.
+func ResetTimer(b *testing.B) {
+	if running != nil {
+		runtime.GC()
+		running.reset()
+	}
+	b.ResetTimer()
+}
+
+func readGCMetrics() []metrics.Sample {
+	s := []metrics.Sample{
+		{Name: metricGCCycles},
+		{Name: metricGCPauses},
+		{Name: metricHeapInUse},
+	}
+	metrics.Read(s)
+	return s
+}
+
+func (m *gcMetrics) sample() {
+	defer close(m.done)
+	t := time.NewTicker(HeapSampleInterval)
+	defer t.Stop()
+	s := []metrics.Sample{{Name: metricHeapInUse}}
+	for {
+		select {
+		case <-m.stop:
+			return
+		case <-t.C:
+			metrics.Read(s)
+			m.observe(s[0].Value.Uint64())
+		}
+	}
+}
+
+func (m *gcMetrics) observe(heap uint64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if heap > m.peak {
+		m.peak = heap
+	}
+}
+
+// Report stops measuring and reports the GC cycles and the GC pause time
+// per operation as well as the peak heap in-use above the baseline
+// if EnvPeakHeap is set. Must be called after the benchmark loop,
+// stops the timer of b so that reading the metrics isn't measured.
+func (m *gcMetrics) Report(b *testing.B) {
+	b.StopTimer()
+	s := readGCMetrics()
+	running = nil
+	if m.stop != nil {
+		close(m.stop)
+		<-m.done
+	}
+	m.observe(s[2].Value.Uint64())
+	if b.N < 1 {
+		return
+	}
+	n := float64(b.N)
+	if m.stop != nil {
+		b.ReportMetric(float64(m.peak-m.base), results.UnitPeakHeapBytes)
+	}
+	b.ReportMetric(float64(s[0].Value.Uint64()-m.cycles)/n, results.UnitGCPerOp)
+	pause := histogramSum(s[1].Value.Float64Histogram()) - histogramSum(m.pauses)
+	b.ReportMetric(pause*1e9/n, results.UnitGCPauseNsPerOp)
+}
+
+// histogramSum estimates the sum of all observations of h
+// using the midpoints of the buckets.
+func histogramSum(h *metrics.Float64Histogram) (sum float64) {
+	for i, c := range h.Counts {
+		if c == 0 {
+			continue
+		}
+		lo, hi := h.Buckets[i], h.Buckets[i+1]
+		var v float64
+		switch {
+		case math.IsInf(lo, -1):
+			v = hi
+		case math.IsInf(hi, 1):
+			v = lo
+		default:
+			v = (lo + hi) / 2
+		}
+		sum += v * float64(c)
+	}
+	return sum
+}
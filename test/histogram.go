@@ -0,0 +1,95 @@
+package test
+
+import "math/bits"
+
+// HistogramSubBits is the number of bits of precision of Histogram.
+// Recorded values are rounded to at most 1/2^(HistogramSubBits-1) (<1%)
+// of their magnitude.
+const HistogramSubBits = 8
+
+// Histogram is an HDR-style histogram of non-negative integers.
+// Values below 2^HistogramSubBits are counted exactly, larger values
+// are counted in buckets whose width doubles with every power of two.
+// The zero value is an empty histogram.
+type Histogram struct {
+	counts []uint64
+	total  uint64
+	min    int64
+	max    int64
+}
+
+const histogramSubCount = 1 << HistogramSubBits
+
+// histogramIndex returns the index of the bucket of v.
+func histogramIndex(v int64) int {
+	if v < histogramSubCount {
+		return int(v)
+	}
+	// Shift v so that its top HistogramSubBits bits remain.
+	shift := bits.Len64(uint64(v)) - HistogramSubBits
+	return shift*histogramSubCount/2 + int(v>>shift)
+}
+
+// histogramHighest returns the highest value counted in bucket i.
+func histogramHighest(i int) int64 {
+	if i < histogramSubCount {
+		return int64(i)
+	}
+	shift := i/(histogramSubCount/2) - 1
+	sub := int64(i - shift*histogramSubCount/2)
+	return (sub+1)<<shift - 1
+}
+
+// Record counts v. Negative values are counted as 0.
+func (h *Histogram) Record(v int64) {
+	if v < 0 {
+		v = 0
+	}
+	i := histogramIndex(v)
+	if i >= len(h.counts) {
+		c := make([]uint64, i+1)
+		copy(c, h.counts)
+		h.counts = c
+	}
+	h.counts[i]++
+	if h.total == 0 || v < h.min {
+		h.min = v
+	}
+	if v > h.max {
+		h.max = v
+	}
+	h.total++
+}
+
+// Count returns the number of recorded values.
+func (h *Histogram) Count() uint64 { return h.total }
+
+// Min returns the smallest recorded value.
+func (h *Histogram) Min() int64 { return h.min }
+
+// Max returns the largest recorded value.
+func (h *Histogram) Max() int64 { return h.max }
+
+// Quantile returns the value below or equal to which
+// the fraction q of all recorded values lies, for example
+// Quantile(0.99) is the 99th percentile. The result is the highest
+// value of the bucket of the quantile capped at Max.
+func (h *Histogram) Quantile(q float64) int64 {
+	if h.total == 0 {
+		return 0
+	}
+	rank := uint64(q * float64(h.total))
+	if float64(rank) < q*float64(h.total) {
+		rank++ // Ceil
+	}
+	if rank < 1 {
+		rank = 1
+	}
+	var n uint64
+	for i, c := range h.counts {
+		if n += c; n >= rank {
+			return min(histogramHighest(i), h.max)
+		}
+	}
+	return h.max
+}
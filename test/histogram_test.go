@@ -0,0 +1,42 @@
+package test_test
+
+import (
+	"testing"
+
+	"github.com/romshark/jscan-benchmark/test"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestHistogram(t *testing.T) {
+	var h test.Histogram
+	require.Zero(t, h.Quantile(0.5))
+
+	for v := int64(1); v <= 1000; v++ {
+		h.Record(v)
+	}
+	h.Record(1_000_000)
+	h.Record(-5)
+
+	require.Equal(t, uint64(1002), h.Count())
+	require.Equal(t, int64(0), h.Min())
+	require.Equal(t, int64(1_000_000), h.Max())
+	require.Equal(t, int64(1_000_000), h.Quantile(1))
+
+	// Values below 2^HistogramSubBits are exact.
+	require.Equal(t, int64(100), h.Quantile(0.1))
+
+	// Larger values are within the precision of the histogram.
+	for _, x := range []struct {
+		q      float64
+		expect int64
+	}{
+		{0.5, 500},
+		{0.9, 901},
+		{0.99, 991},
+	} {
+		v := h.Quantile(x.q)
+		require.GreaterOrEqual(t, v, x.expect, x.q)
+		require.LessOrEqual(t, float64(v), float64(x.expect)*1.01, x.q)
+	}
+}
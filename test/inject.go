@@ -0,0 +1,50 @@
+package test
+
+import (
+	"fmt"
+
+	"github.com/romshark/jscan/v2"
+)
+
+// ErrorPosition is the relative position in a document
+// at which InjectError places a syntax error.
+type ErrorPosition struct {
+	Name string
+	At   float64 // From 0 (start) to 1 (end)
+}
+
+// ErrorPositions are the positions syntax errors are injected at.
+var ErrorPositions = []ErrorPosition{
+	{Name: "start", At: 0},
+	{Name: "middle", At: 0.5},
+	{Name: "end", At: 1},
+}
+
+// InjectedErrorByte replaces the first byte of a value to make it invalid.
+const InjectedErrorByte = '#'
+
+// InjectError returns a copy of the valid JSON document src with the
+// first byte of the last value starting at or before at*len(src)
+// replaced by InjectedErrorByte, and the index of the replaced byte.
+// A document is invalid from its first byte if at is 0
+// and invalid after its last value if at is 1.
+func InjectError(src []byte, at float64) ([]byte, int, error) {
+	limit := int(at * float64(len(src)))
+	index := -1
+	if err := jscan.Scan(src, func(i *jscan.Iterator[[]byte]) (err bool) {
+		if i.ValueIndex() > limit {
+			return true
+		}
+		index = i.ValueIndex()
+		return false
+	}); err.IsErr() && err.Code != jscan.ErrorCodeCallback {
+		return nil, 0, fmt.Errorf("invalid document: %s", err.Error())
+	}
+	if index == -1 {
+		return nil, 0, fmt.Errorf("no value at %v", at)
+	}
+	b := make([]byte, len(src))
+	copy(b, src)
+	b[index] = InjectedErrorByte
+	return b, index, nil
+}
@@ -0,0 +1,31 @@
+package test_test
+
+import (
+	"testing"
+
+	"github.com/romshark/jscan-benchmark/test"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestInjectError(t *testing.T) {
+	src := []byte(`{"a": [1, "#", true], "b": null}`)
+	for _, x := range []struct {
+		at     float64
+		expect string
+		index  int
+	}{
+		{0, `#"a": [1, "#", true], "b": null}`, 0},
+		{0.4, `{"a": [1, ##", true], "b": null}`, 10},
+		{1, `{"a": [1, "#", true], "b": #ull}`, 27},
+	} {
+		b, i, err := test.InjectError(src, x.at)
+		require.NoError(t, err, x.at)
+		require.Equal(t, x.expect, string(b), x.at)
+		require.Equal(t, x.index, i, x.at)
+	}
+	require.Equal(t, `{"a": [1, "#", true], "b": null}`, string(src))
+
+	_, _, err := test.InjectError([]byte(`[`), 0)
+	require.Error(t, err)
+}
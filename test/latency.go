@@ -0,0 +1,77 @@
+package test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/romshark/jscan-benchmark/results"
+)
+
+// LatencyBatch is the number of operations timed before
+// their latencies are recorded with the benchmark timer stopped.
+const LatencyBatch = 1024
+
+// Quantiles are the latency quantiles reported by RunLatency.
+var Quantiles = []struct {
+	Q    float64
+	Unit string
+}{
+	{0.5, results.UnitP50Ns},
+	{0.9, results.UnitP90Ns},
+	{0.99, results.UnitP99Ns},
+	{0.999, results.UnitP999Ns},
+}
+
+// RunLatency runs valid as sub-benchmark name of b like Run timing every
+// operation individually and reports the latency distribution.
+// The verdict of valid is verified against input once before timing.
+// Latencies are recorded into a Histogram in batches of LatencyBatch
+// with the timer stopped, so ns/op includes the overhead of reading
+// the clock but not of recording. The overhead of reading the clock
+// is subtracted from every latency. Heap and GC metrics aren't reported
+// since sampling them would perturb the tail latencies.
+func RunLatency(
+	b *testing.B, name string, input Input, t Throughput, valid func() bool,
+) bool {
+	return run(b, name, t, false, func(b *testing.B) {
+		input.Verify(b, valid())
+		var h Histogram
+		overhead := clockOverhead()
+		buf := make([]time.Duration, LatencyBatch)
+		b.ResetTimer()
+		for n := 0; n < b.N; n += len(buf) {
+			batch := buf[:min(len(buf), b.N-n)]
+			for i := range batch {
+				start := time.Now()
+				latencySink = valid()
+				batch[i] = time.Since(start)
+			}
+			b.StopTimer()
+			for _, d := range batch {
+				h.Record(int64(d - overhead))
+			}
+			b.StartTimer()
+		}
+		b.StopTimer()
+		for _, q := range Quantiles {
+			b.ReportMetric(float64(h.Quantile(q.Q)), q.Unit)
+		}
+		b.ReportMetric(float64(h.Max()), results.UnitMaxNs)
+	})
+}
+
+// latencySink is the sink of the verdicts of RunLatency.
+var latencySink bool
+
+// clockOverhead returns the smallest measurable duration
+// between two consecutive clock readings.
+func clockOverhead() time.Duration {
+	o := time.Duration(1<<63 - 1)
+	for i := 0; i < 1000; i++ {
+		start := time.Now()
+		if d := time.Since(start); d < o {
+			o = d
+		}
+	}
+	return o
+}
@@ -0,0 +1,68 @@
+package test
+
+import (
+	"fmt"
+	"os"
+	"runtime"
+	"runtime/pprof"
+)
+
+// EnvProfile is the environment variable that, when set to a path prefix,
+// makes Run profile the benchmarks it runs but not the loading of their
+// inputs. The CPU profile is written to <prefix>.cpu.pprof and the heap
+// profile to <prefix>.mem.pprof. The heap profile taken before the first
+// benchmark is written to <prefix>.mem.base.pprof to be subtracted
+// using pprof -base.
+const EnvProfile = "JSCANBENCH_PROFILE"
+
+var profile = struct {
+	prefix  string
+	started bool
+	cpu     *os.File
+}{prefix: os.Getenv(EnvProfile)}
+
+// startProfile starts profiling before the first benchmark is run.
+func startProfile() error {
+	if profile.prefix == "" || profile.started {
+		return nil
+	}
+	profile.started = true
+	f, err := os.Create(profile.prefix + ".cpu.pprof")
+	if err != nil {
+		return err
+	}
+	if err := pprof.StartCPUProfile(f); err != nil {
+		f.Close()
+		return err
+	}
+	profile.cpu = f
+	// Taken after starting the CPU profile to exclude its allocations.
+	return writeHeapProfile(profile.prefix + ".mem.base.pprof")
+}
+
+// stopProfile stops profiling and writes the heap profile
+// if profiling was started.
+func stopProfile() error {
+	if !profile.started {
+		return nil
+	}
+	pprof.StopCPUProfile()
+	if err := profile.cpu.Close(); err != nil {
+		return err
+	}
+	return writeHeapProfile(profile.prefix + ".mem.pprof")
+}
+
+func writeHeapProfile(path string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	// Allocations are only recorded in the profile after a collection.
+	runtime.GC()
+	if err := pprof.Lookup("heap").WriteTo(f, 0); err != nil {
+		f.Close()
+		return fmt.Errorf("writing heap profile: %w", err)
+	}
+	return f.Close()
+}
@@ -0,0 +1,132 @@
+package test
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// EnvSelect is the environment variable selecting which suites,
+// inputs and libraries are benchmarked. It consists of key=value pairs
+// separated by semicolons or spaces where value is a comma-separated
+// list of alternatives, for example:
+//
+//	JSCANBENCH_SELECT="lib=jscan,sonic;input=array;category=string-heavy"
+//
+// Keys are "suite", "input", "lib" and "category". A case is selected
+// if it matches any of the alternatives of every key.
+// Names match if they are equal to the value or contain it as a sequence of
+// underscore-separated tokens, "jscan" matches "romshark_jscan"
+// and "array" matches "array_str_1024_639k". Inputs are also selected by
+// "input" if they are of the category named by the value, "input=large"
+// selects "large_26m" and all other inputs of the large category.
+const EnvSelect = "JSCANBENCH_SELECT"
+
+// Selection filters suites, inputs and libraries.
+// Empty lists select everything.
+type Selection struct {
+	Suites     []string
+	Inputs     []string
+	Libraries  []string
+	Categories []string
+}
+
+// ParseSelection parses the value of EnvSelect.
+func ParseSelection(s string) (Selection, error) {
+	var sel Selection
+	for _, p := range strings.FieldsFunc(s, func(r rune) bool {
+		return r == ';' || r == ' '
+	}) {
+		k, v, ok := strings.Cut(p, "=")
+		if !ok || v == "" {
+			return sel, fmt.Errorf("invalid selector %q, expected key=value", p)
+		}
+		values := strings.Split(v, ",")
+		switch k {
+		case "suite":
+			sel.Suites = append(sel.Suites, values...)
+		case "input":
+			sel.Inputs = append(sel.Inputs, values...)
+		case "lib":
+			sel.Libraries = append(sel.Libraries, values...)
+		case "category":
+			sel.Categories = append(sel.Categories, values...)
+		default:
+			return sel, fmt.Errorf("unknown selector key %q", k)
+		}
+	}
+	return sel, nil
+}
+
+// Suite returns true if suite is selected.
+func (s Selection) Suite(suite string) bool { return matchAny(s.Suites, suite) }
+
+// Library returns true if the library with the given benchmark name
+// is selected.
+func (s Selection) Library(name string) bool { return matchAny(s.Libraries, Unpad(name)) }
+
+// Input returns true if the input is selected by name or category
+// and by category.
+func (s Selection) Input(in Input) bool {
+	if !matchAny(s.Inputs, in.Name) && !hasAnyCategory(in, s.Inputs) {
+		return false
+	}
+	return len(s.Categories) < 1 || hasAnyCategory(in, s.Categories)
+}
+
+// hasAnyCategory returns true if in is of any of the categories.
+func hasAnyCategory(in Input, categories []string) bool {
+	for _, c := range categories {
+		if in.HasCategory(c) {
+			return true
+		}
+	}
+	return false
+}
+
+func matchAny(values []string, name string) bool {
+	if len(values) < 1 {
+		return true
+	}
+	for _, v := range values {
+		if MatchName(v, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// MatchName returns true if name equals v or contains v
+// as a sequence of underscore-separated tokens.
+func MatchName(v, name string) bool {
+	return name == v ||
+		strings.HasPrefix(name, v+"_") ||
+		strings.HasSuffix(name, "_"+v) ||
+		strings.Contains(name, "_"+v+"_")
+}
+
+var selected, selectedErr = ParseSelection(os.Getenv(EnvSelect))
+
+// suiteName returns the name of the suite being run.
+// Test binaries are executed in the directory of their package.
+func suiteName() string {
+	wd, err := os.Getwd()
+	if err != nil {
+		return ""
+	}
+	return filepath.Base(wd)
+}
+
+// SkipUnselected skips b if the suite or the input aren't selected
+// by EnvSelect. Must be called at the beginning of the input sub-benchmark.
+func SkipUnselected(b *testing.B, in Input) {
+	b.Helper()
+	if selectedErr != nil {
+		b.Fatalf("%s: %v", EnvSelect, selectedErr)
+	}
+	if !selected.Suite(suiteName()) || !selected.Input(in) {
+		b.SkipNow()
+	}
+}
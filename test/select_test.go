@@ -0,0 +1,68 @@
+package test_test
+
+import (
+	"testing"
+
+	"github.com/romshark/jscan-benchmark/test"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseSelection(t *testing.T) {
+	s, err := test.ParseSelection(
+		"lib=jscan,sonic;input=large category=string-heavy;suite=validation",
+	)
+	require.NoError(t, err)
+	require.Equal(t, test.Selection{
+		Suites:     []string{"validation"},
+		Inputs:     []string{"large"},
+		Libraries:  []string{"jscan", "sonic"},
+		Categories: []string{"string-heavy"},
+	}, s)
+
+	for _, input := range []string{"lib", "lib=", "foo=bar"} {
+		_, err := test.ParseSelection(input)
+		require.Error(t, err, input)
+	}
+}
+
+func TestSelection(t *testing.T) {
+	s, err := test.ParseSelection("lib=jscan,sonic,jsoniter;input=large;category=string-heavy")
+	require.NoError(t, err)
+
+	require.True(t, s.Suite("array2d_int"))
+
+	require.True(t, s.Library("jscan___________"))
+	require.True(t, s.Library("romshark_jscan"))
+	require.True(t, s.Library("bytedance_sonic_"))
+	require.True(t, s.Library("jsoniter_unmarshal"))
+	require.False(t, s.Library("gofaster_jx_____"))
+	require.False(t, s.Library("jscanx"))
+
+	large26m, _ := test.Lookup("large_26m")
+	nasa, _ := test.Lookup("nasa_SxSW_2016_125k")
+	escaped, _ := test.Lookup("escaped_3k")
+	require.True(t, s.Input(large26m))
+	require.False(t, s.Input(nasa), "not string-heavy")
+	require.False(t, s.Input(escaped), "not large")
+
+	var all test.Selection
+	require.True(t, all.Suite("validation"))
+	require.True(t, all.Library("jeffail_gabs____"))
+	require.True(t, all.Input(nasa))
+}
+
+func TestSelectionInputCategory(t *testing.T) {
+	arrayStr, _ := test.Lookup("array_str_1024_639k")
+	require.True(t, arrayStr.HasCategory(test.CategoryLarge))
+	large26m, _ := test.Lookup("large_26m")
+	tiny, _ := test.Lookup("tiny_8b")
+
+	for _, sel := range []string{"input=large", "category=large"} {
+		s, err := test.ParseSelection(sel)
+		require.NoError(t, err)
+		require.True(t, s.Input(arrayStr), sel)
+		require.True(t, s.Input(large26m), sel)
+		require.False(t, s.Input(tiny), sel)
+	}
+}
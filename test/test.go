@@ -26,7 +26,8 @@ var _ SourceProvider = SrcFile("")
 func (s SrcFile) GetJSON() ([]byte, error) {
 	p := filepath.Join("..", "testdata", string(s))
 	switch {
-	case strings.HasSuffix(string(s), ".json"):
+	case strings.HasSuffix(string(s), ".json"),
+		strings.HasSuffix(string(s), ".ndjson"):
 		return os.ReadFile(p)
 	case strings.HasSuffix(string(s), ".gz"):
 		f, err := os.Open(p)
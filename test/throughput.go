@@ -0,0 +1,88 @@
+package test
+
+import (
+	"os"
+	"testing"
+
+	"github.com/romshark/jscan-benchmark/results"
+
+	"github.com/romshark/jscan/v2"
+)
+
+// Throughput is the amount of work done by a single benchmark operation.
+type Throughput struct {
+	Bytes     int
+	Values    int
+	Documents int
+}
+
+// Set makes b report bytes processed per operation as MB/s.
+func (t Throughput) Set(b *testing.B) { b.SetBytes(int64(t.Bytes)) }
+
+// Report reports values and documents processed per second.
+// Must be called after the benchmark loop.
+func (t Throughput) Report(b *testing.B) {
+	s := b.Elapsed().Seconds()
+	if s <= 0 {
+		return
+	}
+	if t.Values > 0 {
+		b.ReportMetric(float64(t.Values)*float64(b.N)/s, results.UnitValuesPerSec)
+	}
+	if t.Documents > 0 {
+		b.ReportMetric(float64(t.Documents)*float64(b.N)/s, results.UnitDocumentsPerSec)
+	}
+}
+
+// EnvList is the environment variable that, when set to "1",
+// makes Run register sub-benchmarks without running them.
+// This allows enumerating all benchmark cases quickly.
+// Only benchmarks run through Run or RunLatency are registered,
+// so suites must run all of their leaf benchmarks through them.
+const EnvList = "JSCANBENCH_LIST"
+
+var listOnly = os.Getenv(EnvList) == "1"
+
+// Run runs fn as sub-benchmark name of b reporting throughput t
+// as well as the heap and GC metrics.
+// Libraries not selected by EnvSelect aren't run.
+func Run(b *testing.B, name string, t Throughput, fn func(b *testing.B)) bool {
+	return run(b, name, t, true, fn)
+}
+
+// run is Run reporting the heap and GC metrics only if gc is true.
+func run(b *testing.B, name string, t Throughput, gc bool, fn func(b *testing.B)) bool {
+	if !selected.Library(name) {
+		return true
+	}
+	return b.Run(name, func(b *testing.B) {
+		if listOnly {
+			return
+		}
+		if err := startProfile(); err != nil {
+			b.Fatalf("starting profile: %v", err)
+		}
+		t.Set(b)
+		if !gc {
+			fn(b)
+			t.Report(b)
+			return
+		}
+		m := startGCMetrics()
+		fn(b)
+		m.Report(b)
+		t.Report(b)
+	})
+}
+
+// CountValues returns the number of JSON values in src
+// or 0 if src isn't valid JSON.
+func CountValues(src []byte) (n int) {
+	if err := jscan.Scan(src, func(*jscan.Iterator[[]byte]) (err bool) {
+		n++
+		return false
+	}); err.IsErr() {
+		return 0
+	}
+	return n
+}
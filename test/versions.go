@@ -0,0 +1,197 @@
+package test
+
+import (
+	"bufio"
+	"bytes"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"runtime/debug"
+	"strings"
+	"testing"
+)
+
+// StdlibJSON is the versions table entry of encoding/json,
+// which is versioned with the Go toolchain.
+const StdlibJSON = "encoding/json"
+
+// Modules are the modules of the benchmarked libraries
+// in the order of the versions table.
+var Modules = []string{
+	"github.com/romshark/jscan/v2",
+	"github.com/go-faster/jx",
+	"github.com/json-iterator/go",
+	"github.com/tidwall/gjson",
+	"github.com/valyala/fastjson",
+	"github.com/goccy/go-json",
+	"github.com/bytedance/sonic",
+	"github.com/ohler55/ojg",
+	"github.com/minio/simdjson-go",
+	"github.com/Jeffail/gabs",
+}
+
+// Version is the version of a benchmarked library.
+type Version struct {
+	Path    string
+	Version string
+}
+
+// Versions returns the versions of encoding/json and all of Modules
+// linked into the running binary as recorded in its build info.
+// Test binaries built before Go 1.24, including those built with the
+// pinned Go 1.21.5, have no module information, in which case the
+// versions required by the enclosing go.mod are returned instead.
+// Those may differ from the versions actually linked, for example when
+// building in a workspace, which is why runbench records the versions
+// selected at build time instead (see runner.Suite.ListVersions).
+func Versions() []Version {
+	l := []Version{{Path: StdlibJSON, Version: runtime.Version()}}
+	deps := buildInfoVersions()
+	if len(deps) < 1 {
+		deps = goModVersions()
+	}
+	for _, m := range Modules {
+		if v, ok := deps[m]; ok {
+			l = append(l, Version{Path: m, Version: v})
+		}
+	}
+	return l
+}
+
+func buildInfoVersions() map[string]string {
+	bi, ok := debug.ReadBuildInfo()
+	if !ok {
+		return nil
+	}
+	m := make(map[string]string, len(bi.Deps))
+	for _, d := range bi.Deps {
+		v := d.Version
+		if d.Replace != nil {
+			v = d.Replace.Version
+			if v == "" {
+				// Replaced by a local directory.
+				v = "(devel)"
+			}
+		}
+		m[d.Path] = v
+	}
+	return m
+}
+
+// goModVersions returns the required versions of the go.mod file
+// of the current directory or its closest parent.
+func goModVersions() map[string]string {
+	dir, err := os.Getwd()
+	if err != nil {
+		return nil
+	}
+	for {
+		if b, err := os.ReadFile(filepath.Join(dir, "go.mod")); err == nil {
+			m, _ := ParseGoMod(b)
+			return m
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return nil
+		}
+		dir = parent
+	}
+}
+
+// ParseGoMod returns the required module versions and the toolchain
+// of the go.mod file b. Replacements with a version take precedence
+// over requirements, replacements by local directories are reported
+// as "(devel)".
+func ParseGoMod(b []byte) (versions map[string]string, toolchain string) {
+	versions = map[string]string{}
+	replace := map[string]string{}
+	var block string
+	sc := bufio.NewScanner(bytes.NewReader(b))
+	for sc.Scan() {
+		l, _, _ := strings.Cut(sc.Text(), "//")
+		f := strings.Fields(l)
+		if len(f) < 1 {
+			continue
+		}
+		switch {
+		case f[0] == ")":
+			block = ""
+			continue
+		case len(f) == 2 && f[1] == "(":
+			block = f[0]
+			continue
+		case f[0] == "toolchain" && len(f) == 2:
+			toolchain = f[1]
+			continue
+		}
+		directive := block
+		if f[0] == "require" || f[0] == "replace" {
+			directive, f = f[0], f[1:]
+		}
+		switch directive {
+		case "require":
+			if len(f) == 2 {
+				versions[f[0]] = f[1]
+			}
+		case "replace":
+			if i := indexOf(f, "=>"); i > 0 {
+				r := f[i+1:]
+				switch len(r) {
+				case 1:
+					replace[f[0]] = "(devel)"
+				case 2:
+					replace[f[0]] = r[1]
+				}
+			}
+		}
+	}
+	for m, v := range replace {
+		if _, ok := versions[m]; ok {
+			versions[m] = v
+		}
+	}
+	return versions, toolchain
+}
+
+func indexOf(l []string, s string) int {
+	for i, x := range l {
+		if x == s {
+			return i
+		}
+	}
+	return -1
+}
+
+// WriteVersions writes versions as benchmark configuration lines
+// of the form "version/<path>: <version>" to w.
+func WriteVersions(w io.Writer, versions []Version) error {
+	for _, v := range versions {
+		if _, err := fmt.Fprintf(w, "version/%s: %s\n", v.Path, v.Version); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Main runs the tests of a suite and prints the library versions
+// before the benchmark results if benchmarks are run.
+// The profile requested through EnvProfile is written at the end.
+// Must be called by TestMain of every suite.
+func Main(m *testing.M) {
+	flag.Parse()
+	if f := flag.Lookup("test.bench"); f != nil && f.Value.String() != "" {
+		if err := WriteVersions(os.Stdout, Versions()); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+	}
+	code := m.Run()
+	if err := stopProfile(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		code = 1
+	}
+	os.Exit(code)
+}
@@ -0,0 +1,71 @@
+package test_test
+
+import (
+	"os"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/romshark/jscan-benchmark/test"
+
+	"github.com/stretchr/testify/require"
+)
+
+var readmeRow = regexp.MustCompile(`^\|(?:pkg\.go\.dev/)?([^|]+)\|\[([^\]]+)\]\(([^)]+)\)\|$`)
+
+func TestREADMEVersions(t *testing.T) {
+	b, err := os.ReadFile("../go.mod")
+	require.NoError(t, err)
+	mods, toolchain := test.ParseGoMod(b)
+	require.NotEmpty(t, toolchain, "go.mod has no toolchain directive")
+
+	b, err = os.ReadFile("../README.md")
+	require.NoError(t, err)
+	type row struct{ version, link string }
+	rows := map[string]row{}
+	for _, l := range strings.Split(string(b), "\n") {
+		if m := readmeRow.FindStringSubmatch(l); m != nil {
+			rows[m[1]] = row{m[2], m[3]}
+		}
+	}
+
+	r, ok := rows[test.StdlibJSON]
+	require.True(t, ok, "%s missing in README", test.StdlibJSON)
+	require.Equal(t, toolchain, r.version, "README version of %s", test.StdlibJSON)
+
+	for _, m := range test.Modules {
+		r, ok := rows[m]
+		require.True(t, ok, "%s missing in README", m)
+		require.Equal(t, mods[m], r.version, "README version of %s", m)
+		require.True(t, strings.HasSuffix(r.link, "/"+mods[m]),
+			"README link of %s doesn't point to %s: %s", m, mods[m], r.link)
+	}
+}
+
+func TestParseGoMod(t *testing.T) {
+	mods, toolchain := test.ParseGoMod([]byte(`module x
+
+go 1.21
+
+toolchain go1.21.5
+
+require a.com/a v1.0.0
+require (
+	b.com/b v1.1.0 // indirect
+	c.com/c v1.2.0
+	d.com/d v1.3.0
+)
+
+replace c.com/c => c.com/fork v1.2.1
+replace (
+	d.com/d => ../d
+)
+`))
+	require.Equal(t, "go1.21.5", toolchain)
+	require.Equal(t, map[string]string{
+		"a.com/a": "v1.0.0",
+		"b.com/b": "v1.1.0",
+		"c.com/c": "v1.2.1",
+		"d.com/d": "(devel)",
+	}, mods)
+}
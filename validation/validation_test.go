@@ -3,17 +3,17 @@ package validation
 import (
 	"testing"
 
+	"github.com/romshark/jscan-benchmark/jscanversions"
 	"github.com/romshark/jscan-benchmark/test"
+	"github.com/romshark/jscan-benchmark/validators"
+	"github.com/romshark/jscan-benchmark/variants"
 
 	"github.com/romshark/jscan/v2"
 
 	encodingjson "encoding/json"
 
 	jeffailgabs "github.com/Jeffail/gabs"
-	bytedancesonic "github.com/bytedance/sonic"
-	gofasterjx "github.com/go-faster/jx"
 	goccygojson "github.com/goccy/go-json"
-	jsoniter "github.com/json-iterator/go"
 	miniosimdjson "github.com/minio/simdjson-go"
 	ohler55ojgoj "github.com/ohler55/ojg/oj"
 	"github.com/stretchr/testify/require"
@@ -21,45 +21,64 @@ import (
 	valyalafastjson "github.com/valyala/fastjson"
 )
 
-var tests = []struct {
-	name  string
-	input test.SourceProvider
-}{
-	{"deeparray_____________", test.SrcMake(func() []byte {
-		return []byte(test.Repeat("[", 1024) + test.Repeat("]", 1024))
-	})},
-	{"unwind_stack__________", test.SrcMake(func() []byte {
-		return []byte(test.Repeat("[", 1024))
-	})},
-	{"miniscule_1b__________", test.SrcFile("miniscule_1b.json")},
-	{"tiny_8b_______________", test.SrcFile("tiny_8b.json")},
-	{"small_336b____________", test.SrcFile("small_336b.json")},
-	{"large_26m_____________", test.SrcFile("large_26m.json.gz")},
-	{"nasa_SxSW_2016_125k___", test.SrcFile("nasa_SxSW_2016_125k.json.gz")},
-	{"escaped_3k____________", test.SrcFile("escaped_3k.json")},
-	{"array_int_1024_12k____", test.SrcFile("array_int_1024_12k.json")},
-	{"array_dec_1024_10k____", test.SrcFile("array_dec_1024_10k.json")},
-	{"array_nullbool_1024_5k", test.SrcFile("array_nullbool_1024_5k.json")},
-	{"array_str_1024_639k___", test.SrcFile("array_str_1024_639k.json")},
-}
+var tests = append([]test.Input{
+	{
+		Name: "deeparray",
+		Source: test.SrcMake(func() []byte {
+			return []byte(test.Repeat("[", 1024) + test.Repeat("]", 1024))
+		}),
+		Values:     1024,
+		Categories: []string{test.CategorySmall},
+	},
+	{
+		Name: "unwind_stack",
+		Source: test.SrcMake(func() []byte {
+			return []byte(test.Repeat("[", 1024))
+		}),
+		Invalid:    true,
+		Categories: []string{test.CategorySmall},
+	},
+}, test.Corpus...)
 
 func TestValid(t *testing.T) {
 	j := `[false,[[2, {"[foo]":[{"bar-baz":"fuz"}]}]]]`
 	require.True(t, encodingjson.Valid([]byte(j)))
 
-	t.Run("jscan___________", func(t *testing.T) {
-		require.True(t, jscan.Valid(j))
-	})
+	for _, v := range variants.JscanStack {
+		t.Run(test.Pad(v.Library("jscan"), 16), func(t *testing.T) {
+			require.True(t, jscan.NewValidator[string](v.Config).Valid(j))
+		})
+	}
 
-	t.Run("jsoniter________", func(t *testing.T) {
-		require.True(t, jsoniter.Valid([]byte(j)))
+	t.Run(test.Pad("jscan_string", 16), func(t *testing.T) {
+		require.True(t, jscan.NewValidator[string](1024).Valid(j))
 	})
 
+	for _, v := range jscanversions.Versions {
+		t.Run(test.Pad(v.Name, 16), func(t *testing.T) {
+			require.True(t, v.NewValidator(1024)([]byte(j)))
+		})
+	}
+
+	for _, v := range variants.Jsoniter {
+		t.Run(test.Pad(v.Library("jsoniter"), 16), func(t *testing.T) {
+			require.True(t, v.Config.Valid([]byte(j)))
+		})
+	}
+
 	t.Run("tidwall_gjson___", func(t *testing.T) {
 		require.True(t, tidwallgjson.Valid(j))
 	})
 
+	t.Run("tidwall_gjson_bytes", func(t *testing.T) {
+		require.True(t, tidwallgjson.ValidBytes([]byte(j)))
+	})
+
 	t.Run("valyala_fastjson", func(t *testing.T) {
+		require.NoError(t, valyalafastjson.ValidateBytes([]byte(j)))
+	})
+
+	t.Run("valyala_fastjson_string", func(t *testing.T) {
 		require.NoError(t, valyalafastjson.Validate(j))
 	})
 
@@ -67,9 +86,11 @@ func TestValid(t *testing.T) {
 		require.True(t, goccygojson.Valid([]byte(j)))
 	})
 
-	t.Run("bytedance_sonic_", func(t *testing.T) {
-		require.True(t, bytedancesonic.ConfigFastest.Valid([]byte(j)))
-	})
+	for _, v := range variants.Sonic {
+		t.Run(test.Pad(v.Library("bytedance_sonic"), 16), func(t *testing.T) {
+			require.True(t, v.Config.Valid([]byte(j)))
+		})
+	}
 
 	t.Run("ohler55_ojg_oj__", func(t *testing.T) {
 		require.NoError(t, ohler55ojgoj.Validate([]byte(j)))
@@ -91,94 +112,57 @@ func TestValid(t *testing.T) {
 
 var GB bool
 
+// stringCopy are the libraries additionally benchmarked as
+// <library>_string_copy validating strings copied to byte slices.
+var stringCopy = map[string]bool{"jscan": true, "encoding_json": true}
+
 func BenchmarkValid(b *testing.B) {
 	for _, bd := range tests {
-		b.Run(bd.name, func(b *testing.B) {
-			src, err := bd.input.GetJSON()
+		b.Run(bd.BenchName(), func(b *testing.B) {
+			test.SkipUnselected(b, bd)
+			src, err := bd.Source.GetJSON()
 			require.NoError(b, err)
-
-			b.Run("jscan___________", func(b *testing.B) {
-				v := jscan.NewValidator[[]byte](1024)
-				b.ResetTimer()
-				for i := 0; i < b.N; i++ {
-					GB = v.Valid(src)
-				}
-			})
-
-			b.Run("encoding_json___", func(b *testing.B) {
-				for i := 0; i < b.N; i++ {
-					GB = encodingjson.Valid(src)
-				}
-			})
-
-			b.Run("jsoniter________", func(b *testing.B) {
-				jb := []byte(src)
-				b.ResetTimer()
-				for i := 0; i < b.N; i++ {
-					GB = jsoniter.Valid(jb)
-				}
-			})
-
-			b.Run("gofaster_jx_____", func(b *testing.B) {
-				jb := []byte(src)
-				d := new(gofasterjx.Decoder)
-				b.ResetTimer()
-				for i := 0; i < b.N; i++ {
-					d.ResetBytes(jb)
-					GB = d.Validate() != nil
-				}
-			})
-
-			b.Run("tidwall_gjson___", func(b *testing.B) {
-				j := string(src)
-				b.ResetTimer()
-				for i := 0; i < b.N; i++ {
-					GB = tidwallgjson.Valid(j)
-				}
-			})
-
-			b.Run("valyala_fastjson", func(b *testing.B) {
-				for i := 0; i < b.N; i++ {
-					GB = (valyalafastjson.ValidateBytes(src) != nil)
-				}
-			})
-
-			b.Run("goccy_go_json___", func(b *testing.B) {
-				for i := 0; i < b.N; i++ {
-					GB = goccygojson.Valid(src)
-				}
-			})
-
-			b.Run("bytedance_sonic_", func(b *testing.B) {
-				for i := 0; i < b.N; i++ {
-					GB = bytedancesonic.ConfigFastest.Valid(src)
-				}
-			})
-
-			b.Run("ohler55_ojg_oj__", func(b *testing.B) {
-				v := new(ohler55ojgoj.Validator)
-				b.ResetTimer()
-				for i := 0; i < b.N; i++ {
-					GB = v.Validate(src) != nil
-				}
-			})
-
-			b.Run("minio_simdjson__", func(b *testing.B) {
-				if !miniosimdjson.SupportedCPU() {
-					b.Skip("unsupported CPU")
-				}
-				for i := 0; i < b.N; i++ {
-					_, err := miniosimdjson.Parse(src, nil)
-					GB = err != nil
+			tp := bd.Throughput(src)
+
+			for _, v := range validators.All {
+				test.Run(b, test.Pad(v.BytesName(), 16), tp, func(b *testing.B) {
+					if v.Supported != nil && !v.Supported() {
+						b.Skip("unsupported CPU")
+					}
+					valid := v.New()
+					bd.Verify(b, valid(src))
+					test.ResetTimer(b)
+					for i := 0; i < b.N; i++ {
+						GB = valid(src)
+					}
+				})
+
+				if v.NewString != nil {
+					// Inputs held as strings, converted before the timer starts.
+					test.Run(b, test.Pad(v.StringName(), 16), tp, func(b *testing.B) {
+						valid := v.NewString()
+						j := string(src)
+						bd.Verify(b, valid(j))
+						test.ResetTimer(b)
+						for i := 0; i < b.N; i++ {
+							GB = valid(j)
+						}
+					})
 				}
-			})
 
-			b.Run("jeffail_gabs____", func(b *testing.B) {
-				for i := 0; i < b.N; i++ {
-					_, err := jeffailgabs.ParseJSON(src)
-					GB = err != nil
+				if stringCopy[v.Name] {
+					// Inputs held as strings, copied in every operation.
+					test.Run(b, test.Pad(v.Name+"_string_copy", 16), tp, func(b *testing.B) {
+						valid := v.New()
+						j := string(src)
+						bd.Verify(b, valid([]byte(j)))
+						test.ResetTimer(b)
+						for i := 0; i < b.N; i++ {
+							GB = valid([]byte(j))
+						}
+					})
 				}
-			})
+			}
 		})
 	}
 }
@@ -0,0 +1,78 @@
+package validation_errors
+
+import (
+	"testing"
+
+	"github.com/romshark/jscan-benchmark/test"
+	"github.com/romshark/jscan-benchmark/validators"
+
+	"github.com/stretchr/testify/require"
+)
+
+// input is a valid corpus input a syntax error is injected into.
+type input struct {
+	test.Input // Invalid, named after the corpus input and position
+	Position   test.ErrorPosition
+}
+
+func (i input) BenchName() string { return test.Pad(i.Name, 30) }
+
+// inputs are the large documents of the corpus with a syntax error
+// at every position of test.ErrorPositions.
+var inputs = func() (l []input) {
+	for _, n := range []string{
+		"large_26m",
+		"array_int_1024_12k",
+		"array_dec_1024_10k",
+		"array_nullbool_1024_5k",
+		"array_str_1024_639k",
+	} {
+		in, ok := test.Lookup(n)
+		if !ok {
+			panic("input " + n + " not in corpus")
+		}
+		for _, p := range test.ErrorPositions {
+			l = append(l, input{
+				Input: test.Input{
+					Name:       in.Name + "_" + p.Name,
+					Source:     in.Source,
+					Invalid:    true,
+					Categories: in.Categories,
+				},
+				Position: p,
+			})
+		}
+	}
+	return l
+}()
+
+var GB bool
+
+// BenchmarkValidationErrors measures the time it takes every library
+// to reject a document with a syntax error. Only ns/op is reported,
+// throughput is meaningless for errors near the start of the input.
+func BenchmarkValidationErrors(b *testing.B) {
+	for _, bd := range inputs {
+		b.Run(bd.BenchName(), func(b *testing.B) {
+			test.SkipUnselected(b, bd.Input)
+			valid, err := bd.Source.GetJSON()
+			require.NoError(b, err)
+			src, _, err := test.InjectError(valid, bd.Position.At)
+			require.NoError(b, err)
+
+			for _, v := range validators.All {
+				test.Run(b, test.Pad(v.BytesName(), 16), test.Throughput{}, func(b *testing.B) {
+					if v.Supported != nil && !v.Supported() {
+						b.Skip("unsupported CPU")
+					}
+					valid := v.New()
+					bd.Verify(b, valid(src))
+					test.ResetTimer(b)
+					for i := 0; i < b.N; i++ {
+						GB = valid(src)
+					}
+				})
+			}
+		})
+	}
+}
@@ -0,0 +1,243 @@
+package validation_reader
+
+import (
+	"bytes"
+	"io"
+	"strconv"
+	"testing"
+
+	"github.com/romshark/jscan-benchmark/test"
+
+	"github.com/romshark/jscan/v2"
+
+	encodingjson "encoding/json"
+
+	bytedancesonic "github.com/bytedance/sonic"
+	gofasterjx "github.com/go-faster/jx"
+	jsoniter "github.com/json-iterator/go"
+	"github.com/stretchr/testify/require"
+)
+
+// ChunkSizes are the maximum numbers of bytes returned by a single read,
+// from small network packets to large buffered reads.
+var ChunkSizes = []int{512, 4 << 10, 64 << 10}
+
+// BufferSize is the size of the read buffer of streaming libraries.
+const BufferSize = 4 << 10
+
+// chunkReader reads src in chunks of at most size bytes.
+type chunkReader struct {
+	src  []byte
+	size int
+	off  int
+}
+
+func (r *chunkReader) Read(p []byte) (int, error) {
+	if r.off >= len(r.src) {
+		return 0, io.EOF
+	}
+	if len(p) > r.size {
+		p = p[:r.size]
+	}
+	n := copy(p, r.src[r.off:])
+	r.off += n
+	return n, nil
+}
+
+// Reset rewinds the reader to the start of src.
+func (r *chunkReader) Reset() { r.off = 0 }
+
+// input is a corpus input read in chunks of ChunkSize bytes.
+type input struct {
+	test.Input // Named after the corpus input and chunk size
+	ChunkSize  int
+}
+
+func (i input) BenchName() string { return test.Pad(i.Name, 30) }
+
+// inputs are all corpus inputs read in every chunk size.
+var inputs = func() (l []input) {
+	for _, in := range test.Corpus {
+		for _, s := range ChunkSizes {
+			in := in
+			in.Name += "_chunk" + formatSize(s)
+			l = append(l, input{Input: in, ChunkSize: s})
+		}
+	}
+	return l
+}()
+
+func formatSize(n int) string {
+	if n >= 1<<10 {
+		return strconv.Itoa(n>>10) + "k"
+	}
+	return strconv.Itoa(n)
+}
+
+// library validates JSON read from a reader.
+// New returns a validator reusing its state across calls.
+type library struct {
+	Name string
+	New  func() func(r io.Reader) bool
+
+	// SplitsNumbers is true if a number at the top level spanning
+	// multiple reads is split at the read boundaries.
+	SplitsNumbers bool
+
+	// MaxReads is the maximum number of reads of the inputs benchmarked,
+	// inputs requiring more reads are skipped. 0 means unlimited.
+	MaxReads int
+}
+
+// decodeOne returns true if decode decodes exactly one value
+// followed by the end of the input.
+func decodeOne(decode func(v any) error) bool {
+	var v encodingjson.RawMessage
+	return decode(&v) == nil && decode(&v) == io.EOF
+}
+
+var libraries = []library{
+	{
+		// Buffers the whole input before validating it.
+		Name: "jscan_readall",
+		New: func() func(r io.Reader) bool {
+			var buf bytes.Buffer
+			jv := jscan.NewValidator[[]byte](1024)
+			return func(r io.Reader) bool {
+				buf.Reset()
+				if _, err := buf.ReadFrom(r); err != nil {
+					return false
+				}
+				return jv.Valid(buf.Bytes())
+			}
+		},
+	},
+	{
+		Name: "encoding_json",
+		New: func() func(r io.Reader) bool {
+			return func(r io.Reader) bool {
+				return decodeOne(encodingjson.NewDecoder(r).Decode)
+			}
+		},
+	},
+	{
+		Name: "jsoniter",
+		New: func() func(r io.Reader) bool {
+			it := jsoniter.Parse(jsoniter.ConfigDefault, nil, BufferSize)
+			return func(r io.Reader) bool {
+				// Reset doesn't reset the error of the previous input.
+				it.Reset(r)
+				it.Error = nil
+				number := it.WhatIsNext() == jsoniter.NumberValue
+				it.Skip()
+				if number && it.Error == io.EOF {
+					// The number ends at the end of the input.
+					return true
+				}
+				if it.Error != nil || it.WhatIsNext() != jsoniter.InvalidValue {
+					return false
+				}
+				// Only the end of the input is no value without an error.
+				return it.Error == io.EOF
+			}
+		},
+	},
+	{
+		Name: "gofaster_jx",
+		New: func() func(r io.Reader) bool {
+			d := gofasterjx.Decode(nil, BufferSize)
+			return func(r io.Reader) bool {
+				d.Reset(r)
+				return d.Validate() == nil
+			}
+		},
+	},
+	{
+		// The stream decoder can't be reset. It skips everything buffered
+		// after every read until a whole value is buffered, which takes time
+		// quadratic in the number of reads and splits numbers at the top level
+		// at read boundaries. Decoding trailing space fails with a syntax error
+		// instead of io.EOF.
+		Name: "bytedance_sonic",
+		New: func() func(r io.Reader) bool {
+			return func(r io.Reader) bool {
+				d := bytedancesonic.ConfigFastest.NewDecoder(r)
+				var v encodingjson.RawMessage
+				if d.Decode(&v) != nil || d.More() {
+					return false
+				}
+				// More skipped space up to the end of the input
+				// or a closing bracket.
+				rest, err := io.ReadAll(d.Buffered())
+				return err == nil && len(bytes.TrimSpace(rest)) == 0
+			}
+		},
+		SplitsNumbers: true,
+		MaxReads:      2048,
+	},
+}
+
+func TestValidReader(t *testing.T) {
+	for _, l := range libraries {
+		t.Run(test.Pad(l.Name, 16), func(t *testing.T) {
+			valid := l.New()
+			for _, c := range []struct {
+				input  string
+				valid  bool
+				number bool // At the top level
+			}{
+				{`[false,[[2, {"[foo]":[{"bar-baz":"fuz"}]}]]]`, true, false},
+				{" {\"a\": \"\\u00e9\"}\n", true, false},
+				{`"abc"`, true, false},
+				{`12`, true, true},
+				{"-1.5e3 ", true, true},
+				{`[1,2]]`, false, false},
+				{`{"a":1} {}`, false, false},
+				{`[1,2`, false, false},
+				{`[1,#]`, false, false},
+				{``, false, false},
+			} {
+				for _, size := range []int{1, 3, BufferSize} {
+					if c.number && l.SplitsNumbers && size < len(c.input) {
+						continue
+					}
+					r := &chunkReader{src: []byte(c.input), size: size}
+					require.Equal(t, c.valid, valid(r), "%q in chunks of %d", c.input, size)
+				}
+			}
+		})
+	}
+}
+
+var GB bool
+
+// BenchmarkValidReader measures validating documents read from a reader
+// returning at most ChunkSize bytes per read using the streaming APIs
+// of the libraries against buffering the whole document first.
+func BenchmarkValidReader(b *testing.B) {
+	for _, bd := range inputs {
+		b.Run(bd.BenchName(), func(b *testing.B) {
+			test.SkipUnselected(b, bd.Input)
+			src, err := bd.Source.GetJSON()
+			require.NoError(b, err)
+			tp := bd.Throughput(src)
+			r := &chunkReader{src: src, size: bd.ChunkSize}
+
+			for _, l := range libraries {
+				test.Run(b, test.Pad(l.Name, 16), tp, func(b *testing.B) {
+					if l.MaxReads > 0 && len(src) > l.MaxReads*bd.ChunkSize {
+						b.Skipf("requires more than %d reads", l.MaxReads)
+					}
+					valid := l.New()
+					r.Reset()
+					bd.Verify(b, valid(r))
+					test.ResetTimer(b)
+					for i := 0; i < b.N; i++ {
+						r.Reset()
+						GB = valid(r)
+					}
+				})
+			}
+		})
+	}
+}
@@ -0,0 +1,150 @@
+// Package validators is the table of JSON validators shared by the suites
+// benchmarking validation of complete documents held in memory.
+package validators
+
+import (
+	"github.com/romshark/jscan-benchmark/jscanversions"
+	"github.com/romshark/jscan-benchmark/variants"
+
+	"github.com/romshark/jscan/v2"
+
+	encodingjson "encoding/json"
+
+	jeffailgabs "github.com/Jeffail/gabs"
+	gofasterjx "github.com/go-faster/jx"
+	goccygojson "github.com/goccy/go-json"
+	miniosimdjson "github.com/minio/simdjson-go"
+	ohler55ojgoj "github.com/ohler55/ojg/oj"
+	tidwallgjson "github.com/tidwall/gjson"
+	valyalafastjson "github.com/valyala/fastjson"
+)
+
+// Validator is a library or variant validating JSON.
+type Validator struct {
+	Name string
+
+	// New returns a validator reusing its state across calls.
+	New func() func(src []byte) bool
+
+	// NewString is like New for inputs held as strings,
+	// nil if the library only accepts byte slices.
+	NewString func() func(src string) bool
+
+	// StringBare is true for libraries benchmarked validating strings
+	// before byte slices were, see BytesName and StringName.
+	StringBare bool
+
+	// Supported returns false if the library doesn't run on this machine,
+	// nil if it always does.
+	Supported func() bool
+}
+
+// BytesName returns the name of the library validating byte slices,
+// Name+"_bytes" if StringBare, Name otherwise.
+func (v Validator) BytesName() string {
+	if v.StringBare {
+		return v.Name + "_bytes"
+	}
+	return v.Name
+}
+
+// StringName returns the name of the library validating strings,
+// Name if StringBare, Name+"_string" otherwise.
+func (v Validator) StringName() string {
+	if v.StringBare {
+		return v.Name
+	}
+	return v.Name + "_string"
+}
+
+// All are all libraries and their variants.
+var All = func() (l []Validator) {
+	for _, v := range variants.JscanStack {
+		v := v
+		x := Validator{Name: v.Library("jscan"), New: func() func([]byte) bool {
+			return jscan.NewValidator[[]byte](v.Config).Valid
+		}}
+		if v.Bare {
+			x.NewString = func() func(string) bool {
+				return jscan.NewValidator[string](v.Config).Valid
+			}
+		}
+		l = append(l, x)
+	}
+
+	for _, v := range jscanversions.Versions {
+		v := v
+		l = append(l, Validator{Name: v.Name, New: func() func([]byte) bool {
+			return v.NewValidator(1024)
+		}})
+	}
+
+	l = append(l, Validator{Name: "encoding_json", New: func() func([]byte) bool {
+		return encodingjson.Valid
+	}})
+
+	for _, v := range variants.Jsoniter {
+		v := v
+		l = append(l, Validator{Name: v.Library("jsoniter"), New: func() func([]byte) bool {
+			return v.Config.Valid
+		}})
+	}
+
+	l = append(l, Validator{Name: "gofaster_jx", New: func() func([]byte) bool {
+		d := new(gofasterjx.Decoder)
+		return func(src []byte) bool {
+			d.ResetBytes(src)
+			return d.Validate() == nil
+		}
+	}}, Validator{
+		Name:       "tidwall_gjson",
+		StringBare: true,
+		New: func() func([]byte) bool {
+			return tidwallgjson.ValidBytes
+		},
+		NewString: func() func(string) bool {
+			return tidwallgjson.Valid
+		},
+	}, Validator{
+		Name: "valyala_fastjson",
+		New: func() func([]byte) bool {
+			return func(src []byte) bool {
+				return valyalafastjson.ValidateBytes(src) == nil
+			}
+		},
+		NewString: func() func(string) bool {
+			return func(src string) bool {
+				return valyalafastjson.Validate(src) == nil
+			}
+		},
+	}, Validator{Name: "goccy_go_json", New: func() func([]byte) bool {
+		return goccygojson.Valid
+	}})
+
+	for _, v := range variants.Sonic {
+		v := v
+		l = append(l, Validator{Name: v.Library("bytedance_sonic"), New: func() func([]byte) bool {
+			return v.Config.Valid
+		}})
+	}
+
+	l = append(l, Validator{Name: "ohler55_ojg_oj", New: func() func([]byte) bool {
+		v := new(ohler55ojgoj.Validator)
+		return func(src []byte) bool { return v.Validate(src) == nil }
+	}}, Validator{
+		Name:      "minio_simdjson",
+		Supported: miniosimdjson.SupportedCPU,
+		New: func() func([]byte) bool {
+			return func(src []byte) bool {
+				_, err := miniosimdjson.Parse(src, nil)
+				return err == nil
+			}
+		},
+	}, Validator{Name: "jeffail_gabs", New: func() func([]byte) bool {
+		return func(src []byte) bool {
+			_, err := jeffailgabs.ParseJSON(src)
+			return err == nil
+		}
+	}})
+	return l
+}()
@@ -0,0 +1,88 @@
+// Package variants defines the configurations libraries are benchmarked in.
+//
+// Every suite benchmarks each variant of a library as a separate library
+// named after Variant.Library. Every variant is named after its
+// configuration, except for the bare variant reported under the bare
+// library name so that results remain comparable with results recorded
+// before variants were introduced. The bare variant is the configuration
+// a suite benchmarked before, see WithBare, and the first variant of each
+// list for all other suites.
+package variants
+
+import (
+	bytedancesonic "github.com/bytedance/sonic"
+	jsoniter "github.com/json-iterator/go"
+)
+
+// Variant is a named configuration of a library.
+type Variant[C any] struct {
+	Name   string
+	Config C
+
+	// Bare is true for the variant reported under the bare library name.
+	Bare bool
+}
+
+// Library returns the name of the variant of library lib,
+// for example "jsoniter_fastest" or "jsoniter" for the bare variant.
+func (v Variant[C]) Library(lib string) string {
+	if v.Bare {
+		return lib
+	}
+	return lib + "_" + v.Name
+}
+
+// WithBare returns a copy of variants with the variant named name
+// as the bare variant instead.
+func WithBare[C any](variants []Variant[C], name string) []Variant[C] {
+	l := make([]Variant[C], len(variants))
+	found := false
+	for i, v := range variants {
+		v.Bare = v.Name == name
+		found = found || v.Bare
+		l[i] = v
+	}
+	if !found {
+		panic("variants: no variant named " + name)
+	}
+	return l
+}
+
+// Bare returns the bare variant of variants.
+func Bare[C any](variants []Variant[C]) Variant[C] {
+	for _, v := range variants {
+		if v.Bare {
+			return v
+		}
+	}
+	panic("variants: no bare variant")
+}
+
+// JscanStack are the numbers of stack frames preallocated by the jscan
+// validator and parser.
+var JscanStack = []Variant[int]{
+	{Name: "stack1024", Config: 1024, Bare: true},
+	{Name: "stack8", Config: 8},
+	{Name: "stack64", Config: 64},
+}
+
+// Jsoniter are the predefined jsoniter configurations.
+var Jsoniter = []Variant[jsoniter.API]{
+	{Name: "default", Config: jsoniter.ConfigDefault, Bare: true},
+	{Name: "fastest", Config: jsoniter.ConfigFastest},
+	{Name: "compatible", Config: jsoniter.ConfigCompatibleWithStandardLibrary},
+}
+
+// Sonic are the predefined sonic configurations.
+var Sonic = []Variant[bytedancesonic.API]{
+	{Name: "fastest", Config: bytedancesonic.ConfigFastest, Bare: true},
+	{Name: "std", Config: bytedancesonic.ConfigStd},
+	{Name: "default", Config: bytedancesonic.ConfigDefault},
+}
+
+// FastjsonReuse defines whether a fastjson parser is reused
+// across operations or allocated for every operation.
+var FastjsonReuse = []Variant[bool]{
+	{Name: "reuse", Config: true, Bare: true},
+	{Name: "noreuse", Config: false},
+}